@@ -0,0 +1,203 @@
+// Copyright 2021 Siôn le Roux.  All rights reserved.
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+// Package app puts the game's screens together into an Ebitengine game, it's
+// shared by the desktop, web and mobile builds
+package app
+
+import (
+	"errors"
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/freefall/assets"
+	"github.com/sinisterstuf/freefall/game"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+const sampleRate int = 44100 // assuming "normal" sample rate
+
+// displayTPS is how often input is polled, the game logic runs at its own
+// lower rate to match the feel of the Nokia
+const displayTPS = 60
+
+// Options change how the game runs, they're set by command line flags on
+// desktop
+type Options struct {
+	Rate        int  // Game logic ticks per second
+	Interpolate bool // Smooth movement in between logic ticks
+	Hold        bool // Hold the button to keep the chute open instead of toggling it
+	Mute        bool // Start with the sound muted
+	NoAudio     bool // Run without any audio
+	Keypad      bool // Show the on-screen keypad
+}
+
+// DefaultOptions are how the game runs when nothing's changed
+var DefaultOptions = Options{Rate: 15}
+
+// New sets up audio, settings and input and makes the game ready to run
+func New(opts Options) *Game {
+	switch {
+	case opts.NoAudio:
+		log.Println("audio disabled")
+	case !assets.AudioAvailable():
+		log.Println("sound device failed to open, running without audio")
+	default:
+		game.Context = audio.NewContext(sampleRate)
+		game.Mixer = assets.NewMixer(game.Context)
+	}
+
+	game.Config = game.LoadSettings()
+	game.Config.SetFlags(game.Flags{Hold: opts.Hold, Mute: opts.Mute, Keypad: opts.Keypad})
+	game.Config.Apply()
+	game.LoadScores()
+	game.LoadDaily()
+	ebiten.SetWindowTitle("Freefall")
+	ebiten.SetTPS(displayTPS)
+	setupPage()
+
+	input := game.NewInput()
+	game.Vibra = game.NewHaptics(input)
+
+	return &Game{
+		Screens: []game.Entity{
+			game.NewTitleScreen(input),
+			nil, // Runs are made when they start
+			game.NewGameOverScreen(input),
+			game.NewSettingsScreen(input),
+			game.NewHighScoresScreen(input),
+			game.NewCreditsScreen(input),
+			game.NewDailyMenu(input),
+			nil,
+			nil,
+		},
+		Input:       input,
+		Step:        1 / float64(opts.Rate),
+		Interpolate: opts.Interpolate,
+	}
+}
+
+// runs make a fresh run for each screen that plays one, every time it starts
+var runs = map[game.Screen]func(*game.Input) *game.GameScreen{
+	game.ScreenGame:     game.NewGameScreen,
+	game.ScreenDailyRun: game.NewDailyScreen,
+	game.ScreenRace:     game.NewRaceScreen,
+}
+
+// Game represents the main Ebitengine Game state that coordinates screens
+type Game struct {
+	Input       *game.Input   // Buffered input shared by all screens
+	Screens     []game.Entity // A slice of all possible game screens
+	Screen      game.Screen   // The current screen
+	Step        float64       // Seconds per game logic tick
+	Lag         float64       // Seconds of game logic still to catch up on
+	Interpolate bool          // Whether to draw in between logic ticks
+	Canvas      *ebiten.Image // Drawn to first when remapping to another palette
+	size        image.Point   // Screen size at the last layout
+}
+
+// Layout is the Nokia display, with the keypad below it if it's shown
+func (g *Game) Layout(outsideWidth int, outsideHeight int) (screenWidth int, screenHeight int) {
+	size := game.Config.ScreenSize()
+	if size != g.size {
+		g.size = size
+		fitPage(size)
+	}
+	return size.X, size.Y
+}
+
+// Update calculates game logic
+func (g *Game) Update() error {
+
+	// The keypad comes and goes with the setting
+	if game.Config.Keypad && g.Input.Keypad == nil {
+		g.Input.Keypad = game.NewKeypad(nokia.GameSize.Y)
+	} else if !game.Config.Keypad {
+		g.Input.Keypad = nil
+	}
+
+	// Input is polled every frame so presses between logic ticks aren't lost
+	g.Input.Poll()
+
+	// Pressing Q any time quits immediately
+	if ebiten.IsKeyPressed(ebiten.KeyQ) {
+		return game.ErrQuit
+	}
+
+	// Pressing F toggles full-screen
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		game.Config.Fullscreen = !ebiten.IsFullscreen()
+		game.Config.Apply()
+		game.Config.Save()
+	}
+
+	// Pressing M toggles muting all sound
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		game.Config.Muted = !game.Config.Muted
+		game.Config.Apply()
+		game.Config.Save()
+	}
+	game.Mixer.Update()
+
+	// Run as many fixed logic ticks as have built up, so the game plays out
+	// the same way no matter how often frames are drawn
+	g.Lag += 1 / float64(ebiten.TPS())
+	for g.Lag >= g.Step {
+		g.Lag -= g.Step
+		err := g.Screens[g.Screen].Update()
+		g.Input.Step()
+
+		var EOS *game.EOS
+		if errors.As(err, &EOS) {
+			next := EOS.NextScreen
+			log.Println("switching to screen:", next)
+			if newRun, ok := runs[next]; ok {
+				g.Screens[next] = newRun(g.Input)
+			}
+			if next == game.ScreenGameOver {
+				game.SaveScores()
+			}
+			g.Screen = next
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Draw draws the game screen by one frame
+func (g *Game) Draw(screen *ebiten.Image) {
+	// Screens draw in the original palette, it's swapped at the very end
+	target := screen
+	if game.Config.Palette != 0 {
+		if g.Canvas == nil || g.Canvas.Bounds() != screen.Bounds() {
+			g.Canvas = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+		}
+		target = g.Canvas
+	}
+
+	target.Fill(nokia.PaletteOriginal.Light())
+	if i, ok := g.Screens[g.Screen].(game.Interpolator); ok {
+		alpha := 0.0
+		if g.Interpolate {
+			alpha = g.Lag / g.Step
+		}
+		i.SetAlpha(alpha)
+	}
+	display := target.SubImage(image.Rectangle{Max: nokia.GameSize}).(*ebiten.Image)
+	g.Screens[g.Screen].Draw(display)
+	if g.Input.Keypad != nil {
+		g.Input.Keypad.Draw(target)
+	}
+
+	if target != screen {
+		game.DrawPalette(screen, target, nokia.Palettes[game.Config.Palette])
+	}
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"image"
+	"syscall/js"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// setupPage makes the game pause when the page loses focus, like the page
+// says it does
+func setupPage() {
+	ebiten.SetRunnableOnUnfocused(false)
+}
+
+// fitPage tells the page how big the game is so it can scale it by a whole
+// number of pixels
+func fitPage(size image.Point) {
+	if fit := js.Global().Get("fitScreen"); fit.Type() == js.TypeFunction {
+		fit.Invoke(size.X, size.Y)
+	}
+}
+
+// ReportError shows an error that stopped the game on the page
+func ReportError(err error) {
+	if report := js.Global().Get("reportError"); report.Type() == js.TypeFunction {
+		report.Invoke(err.Error())
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !js
+
+package app
+
+import "image"
+
+// setupPage does nothing outside of browsers
+func setupPage() {}
+
+// fitPage does nothing outside of browsers, the window is sized by settings
+func fitPage(size image.Point) {}
+
+// ReportError does nothing outside of browsers, errors are logged instead
+func ReportError(err error) {}
@@ -8,18 +8,23 @@ import (
 	"embed"
 	"encoding/json"
 	"image/png"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"strconv"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/sinisterstuf/freefall/midi"
+	"github.com/sinisterstuf/freefall/ringtone"
 	"github.com/tinne26/etxt"
 )
 
-//go:embed *.png *.json *.ogg *.ttf
+//go:embed *.png *.json *.ogg *.wav *.mid *.ttf
 var assets embed.FS
 
 // Frame is a single frame of an animation, usually a sub-image of a larger
@@ -92,6 +97,49 @@ func LoadSprite(name string) *SpriteSheet {
 	return &ss
 }
 
+// Layer is one background layer of scenery, either repeating endlessly as the
+// box falls past it or anchored in place so it rises up as altitude decreases
+type Layer struct {
+	ImageName string  `json:"image"`
+	Rate      float64 `json:"rate"`   // Pixels scrolled per metre fallen
+	Repeat    bool    `json:"repeat"` // Whether to tile the image vertically
+	Base      int     `json:"base"`   // Screen Y of an anchored layer's bottom edge on the ground
+	Image     *ebiten.Image
+}
+
+// Level is the scenery for one level, layers are drawn back to front
+type Level struct {
+	Name   string  `json:"name"`
+	Layers []Layer `json:"layers"`
+}
+
+// LoadLevels loads the scenery layers of every level from the named JSON file
+// and the images they use
+func LoadLevels(name string) []Level {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		log.Fatalf("error opening file %s: %v\n", name, err)
+	}
+	defer file.Close()
+
+	var data struct {
+		Levels []Level `json:"levels"`
+	}
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		log.Fatalf("error decoding file %s as JSON: %v\n", name, err)
+	}
+
+	for _, l := range data.Levels {
+		for i := range l.Layers {
+			l.Layers[i].Image = LoadImage(l.Layers[i].ImageName)
+		}
+	}
+
+	return data.Levels
+}
+
 // Load an image from embedded FS into an ebiten Image object
 func LoadImage(name string) *ebiten.Image {
 	log.Printf("loading %s\n", name)
@@ -190,9 +238,29 @@ func NewMusicPlayer(music *vorbis.Stream, context *audio.Context) *audio.Player
 	return musicPlayer
 }
 
+// NewLoopPlayer loads a sound into an audio player that plays an intro once and
+// then loops the part after it forever, a loop of 0 loops everything after the
+// intro
+func NewLoopPlayer(music *wav.Stream, intro, loop time.Duration, context *audio.Context) *audio.Player {
+	const bytesPerSample = 4 // 16-bit stereo
+	offset := func(d time.Duration) int64 {
+		return int64(d.Seconds()*float64(context.SampleRate())) * bytesPerSample
+	}
+	loopLength := offset(loop)
+	if loop == 0 {
+		loopLength = music.Length() - offset(intro)
+	}
+	musicLoop := audio.NewInfiniteLoopWithIntro(music, offset(intro), loopLength)
+	musicPlayer, err := audio.NewPlayer(context, musicLoop)
+	if err != nil {
+		log.Fatalf("error making music player: %v\n", err)
+	}
+	return musicPlayer
+}
+
 // NewSoundPlayer loads a sound into an audio player that can be used to play it
 // without any additional setup required
-func NewSoundPlayer(audioFile *vorbis.Stream, context *audio.Context) *audio.Player {
+func NewSoundPlayer(audioFile io.ReadSeeker, context *audio.Context) *audio.Player {
 	audioPlayer, err := audio.NewPlayer(context, audioFile)
 	if err != nil {
 		log.Fatalf("error making audio player: %v\n", err)
@@ -218,6 +286,43 @@ func LoadSoundFile(name string, sampleRate int) *vorbis.Stream {
 	return music
 }
 
+// Load a WAV sound file, resampled to sampleRate, and return its stream
+func LoadWAVFile(name string, sampleRate int) *wav.Stream {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		log.Fatalf("error opening file %s: %v\n", name, err)
+	}
+	defer file.Close()
+
+	sound, err := wav.DecodeWithSampleRate(sampleRate, file)
+	if err != nil {
+		log.Fatalf("error decoding file %s as WAV: %v\n", name, err)
+	}
+
+	return sound
+}
+
+// LoadMIDI loads a MIDI file as a tune for the synth, all its tracks are
+// flattened into one note at a time with the highest note winning
+func LoadMIDI(name string) []ringtone.Note {
+	log.Printf("loading %s\n", name)
+
+	file, err := assets.Open(name)
+	if err != nil {
+		log.Fatalf("error opening file %s: %v\n", name, err)
+	}
+	defer file.Close()
+
+	m, err := midi.Parse(file)
+	if err != nil {
+		log.Fatalf("error decoding file %s as MIDI: %v\n", name, err)
+	}
+
+	return m.Monophonic(-1)
+}
+
 // Load a font for use with etxt specified by font name
 func LoadFont(name string) *etxt.Font {
 	font, fname, err := etxt.ParseEmbedFontFrom(name, assets)
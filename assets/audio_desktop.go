@@ -0,0 +1,60 @@
+//go:build !js && !android && !ios
+
+package assets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// Audio device check, Ebitengine can't recover if the sound device fails to
+// open and only one audio context can ever be made, so the check opens one in
+// a copy of the game that does nothing else
+const (
+	audioCheckEnv     = "FREEFALL_AUDIO_CHECK"
+	audioCheckTimeout = 5 * time.Second
+)
+
+func init() {
+	if os.Getenv(audioCheckEnv) != "" {
+		if err := openAudio(); err != nil {
+			fmt.Fprintln(os.Stderr, "audio check failed:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+}
+
+// openAudio opens the sound device the same way Ebitengine does
+func openAudio() error {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   44100,
+		ChannelCount: 2,
+		Format:       oto.FormatFloat32LE,
+	})
+	if err != nil {
+		return err
+	}
+	<-ready
+	return ctx.Err()
+}
+
+// AudioAvailable tells if the sound device can be opened, by opening it in a
+// copy of the game
+func AudioAvailable() bool {
+	exe, err := os.Executable()
+	if err != nil {
+		return true // Can't tell, so try anyway
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), audioCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, exe)
+	cmd.Env = append(os.Environ(), audioCheckEnv+"=1")
+	cmd.Stderr = os.Stderr
+	return cmd.Run() == nil
+}
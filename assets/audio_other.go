@@ -0,0 +1,9 @@
+//go:build js || android || ios
+
+package assets
+
+// AudioAvailable tells if there's anything to play sound on, browsers and
+// phones always have something
+func AudioAvailable() bool {
+	return true
+}
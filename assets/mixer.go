@@ -0,0 +1,309 @@
+package assets
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/sinisterstuf/freefall/ringtone"
+)
+
+// Bus is a group of tracks that share a volume
+type Bus int
+
+const (
+	BusMusic Bus = iota // Looping background music
+	BusSFX              // Sound effects
+	BusMax              // How many buses there are
+)
+
+// Channel is a volume that can be muted without forgetting it
+type Channel struct {
+	Volume float64 // From 0 to 1
+	Muted  bool
+}
+
+// Level is how loud the channel actually is
+func (c Channel) Level() float64 {
+	if c.Muted {
+		return 0
+	}
+	return c.Volume
+}
+
+// DefaultDuck is how loud music stays while sound effects are playing
+const DefaultDuck = 0.4
+
+// duckSpeed is how much ducking eases in or out per update
+const duckSpeed = 0.1
+
+// Mixer sets the volume of every track from a master volume, the volume of
+// the track's bus, the track's own fade and ducking of the music while sound
+// effects play
+type Mixer struct {
+	Master  Channel
+	Buses   [BusMax]Channel
+	Duck    float64 // How loud music gets while sound effects play, 1 for no ducking
+	context *audio.Context
+	tracks  map[string]*Track
+	stems   []*Stems
+	voices  map[*Voice]bool
+	sounds  map[string][]byte // Decoded sounds that voices are made from
+	ducked  float64           // Current ducking level, eased towards Duck
+}
+
+// NewMixer makes a mixer with everything at full volume, without a context
+// it's silent and never loads any sound files
+func NewMixer(context *audio.Context) *Mixer {
+	return &Mixer{
+		Master:  Channel{Volume: 1},
+		Buses:   [BusMax]Channel{{Volume: 1}, {Volume: 1}},
+		Duck:    DefaultDuck,
+		context: context,
+		tracks:  map[string]*Track{},
+		voices:  map[*Voice]bool{},
+		sounds:  map[string][]byte{},
+		ducked:  1,
+	}
+}
+
+// Player is what a track needs to play sound, it's an *audio.Player unless
+// there's no audio
+type Player interface {
+	Play()
+	Pause()
+	Rewind() error
+	IsPlaying() bool
+	SetVolume(volume float64)
+	Position() time.Duration
+	Close() error
+}
+
+// nullPlayer is a player that never makes a sound, for when there's no audio
+type nullPlayer struct{}
+
+func (nullPlayer) Play()                   {}
+func (nullPlayer) Pause()                  {}
+func (nullPlayer) Rewind() error           { return nil }
+func (nullPlayer) IsPlaying() bool         { return false }
+func (nullPlayer) SetVolume(float64)       {}
+func (nullPlayer) Position() time.Duration { return 0 }
+func (nullPlayer) Close() error            { return nil }
+
+// Track is an audio player on one of the mixer's buses
+type Track struct {
+	Player
+	Bus    Bus
+	Gain   float64 // Track volume from 0 to 1, changed by fading
+	target float64 // Gain being faded towards
+	fade   float64 // How much Gain changes per update
+	stop   bool    // Whether to stop once faded to silence
+}
+
+// Music loads an OGG file as a looping music track
+func (m *Mixer) Music(name string) *Track {
+	return m.track(name, BusMusic, func() Player {
+		return NewMusicPlayer(LoadSoundFile(name, m.context.SampleRate()), m.context)
+	})
+}
+
+// Sound loads an OGG file as a sound effect track
+func (m *Mixer) Sound(name string) *Track {
+	return m.track(name, BusSFX, func() Player {
+		return NewSoundPlayer(LoadSoundFile(name, m.context.SampleRate()), m.context)
+	})
+}
+
+// Loop loads a WAV file as a music track that plays the intro once and then
+// loops the part after it, a loop of 0 loops everything after the intro
+func (m *Mixer) Loop(name string, intro, loop time.Duration) *Track {
+	return m.track(name, BusMusic, func() Player {
+		return NewLoopPlayer(LoadWAVFile(name, m.context.SampleRate()), intro, loop, m.context)
+	})
+}
+
+// Notes synthesises a ringtone-style tune as a sound effect track
+func (m *Mixer) Notes(name string, notes []ringtone.Note) *Track {
+	return m.track(name, BusSFX, func() Player {
+		return NewSoundPlayer(NewSynth(notes, m.context.SampleRate()), m.context)
+	})
+}
+
+// LoopNotes synthesises a tune as a looping music track
+func (m *Mixer) LoopNotes(name string, notes []ringtone.Note) *Track {
+	return m.track(name, BusMusic, func() Player {
+		synth := NewSynth(notes, m.context.SampleRate())
+		player, err := audio.NewPlayer(m.context, audio.NewInfiniteLoop(synth, synth.Length()))
+		if err != nil {
+			log.Fatalf("error making music player: %v\n", err)
+		}
+		return player
+	})
+}
+
+// track gets the track with a name, tracks are loaded only once and shared
+// after that, and never loaded at all if there's no audio
+func (m *Mixer) track(name string, bus Bus, load func() Player) *Track {
+	if t, ok := m.tracks[name]; ok {
+		return t
+	}
+	var player Player = nullPlayer{}
+	if m.context != nil {
+		player = load()
+	}
+	t := &Track{Player: player, Bus: bus, Gain: 1}
+	m.tracks[name] = t
+	m.updateVolume(t)
+	return t
+}
+
+// Update fades tracks, ducks the music and sets every track's volume, it
+// should be called once per frame
+func (m *Mixer) Update() {
+	duck := 1.0
+	for _, t := range m.tracks {
+		if t.Bus == BusSFX && t.IsPlaying() {
+			duck = m.Duck
+		}
+	}
+	m.ducked += math.Max(-duckSpeed, math.Min(duckSpeed, duck-m.ducked))
+
+	for _, t := range m.tracks {
+		t.step()
+		m.updateVolume(t)
+	}
+	for v := range m.voices {
+		m.updateVolume(v.Track)
+	}
+	for _, s := range m.stems {
+		s.update()
+	}
+}
+
+// Voice is a sound effect that loops with its own panning, for things that
+// make a noise as long as they're around, several voices can play one sound
+type Voice struct {
+	*Track
+	Panner *Panner
+}
+
+// Voice starts looping a WAV file as a new voice, which must be closed when
+// it's no longer needed
+func (m *Mixer) Voice(name string) *Voice {
+	if m.context == nil {
+		return &Voice{Track: &Track{Player: nullPlayer{}, Bus: BusSFX, Gain: 1}, Panner: NewPanner(nil)}
+	}
+
+	pcm, ok := m.sounds[name]
+	if !ok {
+		var err error
+		pcm, err = io.ReadAll(LoadWAVFile(name, m.context.SampleRate()))
+		if err != nil {
+			log.Fatalf("error decoding file %s: %v\n", name, err)
+		}
+		m.sounds[name] = pcm
+	}
+
+	loop := audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm)))
+	panner := NewPanner(loop)
+	player := NewSoundPlayer(panner, m.context)
+	v := &Voice{Track: &Track{Player: player, Bus: BusSFX, Gain: 1}, Panner: panner}
+	m.voices[v] = true
+	m.updateVolume(v.Track)
+	player.Play()
+	return v
+}
+
+// CloseVoice stops a voice for good
+func (m *Mixer) CloseVoice(v *Voice) {
+	if m.voices[v] {
+		delete(m.voices, v)
+		v.Close()
+	}
+}
+
+// CloseVoices stops every voice, e.g. when leaving a screen
+func (m *Mixer) CloseVoices() {
+	for v := range m.voices {
+		m.CloseVoice(v)
+	}
+}
+
+func (m *Mixer) updateVolume(t *Track) {
+	v := m.Master.Level() * m.Buses[t.Bus].Level() * t.Gain
+	if t.Bus == BusMusic {
+		v *= m.ducked
+	}
+	t.SetVolume(v)
+}
+
+// Silent tells if the mixer has no audio at all
+func (m *Mixer) Silent() bool {
+	return m.context == nil
+}
+
+// Muted tells if the master volume is muted
+func (m *Mixer) Muted() bool {
+	return m.Master.Muted
+}
+
+// FadeIn starts playing the track from silence up to full volume
+func (t *Track) FadeIn(d time.Duration) {
+	t.Gain = 0
+	t.FadeTo(1, d)
+	t.Play()
+}
+
+// FadeOut fades the track to silence, then pauses and rewinds it
+func (t *Track) FadeOut(d time.Duration) {
+	t.FadeTo(0, d)
+	t.stop = true
+}
+
+// FadeTo changes the track's volume gradually, it keeps playing throughout
+func (t *Track) FadeTo(gain float64, d time.Duration) {
+	t.target = gain
+	t.fade = fadeStep(d)
+	t.stop = false
+}
+
+// Restart plays the track again from the beginning at full volume
+func (t *Track) Restart() {
+	t.Gain = 1
+	t.fade = 0
+	t.stop = false
+	t.Rewind()
+	t.Play()
+}
+
+func (t *Track) step() {
+	if t.fade == 0 {
+		return
+	}
+	if math.Abs(t.target-t.Gain) <= t.fade {
+		t.Gain = t.target
+		t.fade = 0
+	} else if t.target > t.Gain {
+		t.Gain += t.fade
+	} else {
+		t.Gain -= t.fade
+	}
+	if t.fade == 0 && t.Gain == 0 && t.stop {
+		t.stop = false
+		t.Pause()
+		t.Rewind()
+	}
+}
+
+// fadeStep is how much to change the volume per update to fade over d
+func fadeStep(d time.Duration) float64 {
+	ticks := d.Seconds() * float64(ebiten.TPS())
+	if ticks < 1 {
+		return 1
+	}
+	return 1 / ticks
+}
@@ -0,0 +1,62 @@
+package assets
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Panner is a stream wrapper that moves 16-bit stereo sound between the left
+// and right speakers and makes it quieter, it's safe to change while playing
+type Panner struct {
+	src  io.ReadSeeker
+	pan  atomic.Uint64 // Float bits, -1 for left, 0 for centre, 1 for right
+	gain atomic.Uint64 // Float bits, 0 for silent, 1 for full volume
+}
+
+// NewPanner wraps a stream, it starts in the centre at full volume
+func NewPanner(src io.ReadSeeker) *Panner {
+	p := &Panner{src: src}
+	p.Set(0, 1)
+	return p
+}
+
+// Set moves the sound between the speakers and sets how loud it is
+func (p *Panner) Set(pan, gain float64) {
+	p.pan.Store(math.Float64bits(math.Max(-1, math.Min(1, pan))))
+	p.gain.Store(math.Float64bits(math.Max(0, math.Min(1, gain))))
+}
+
+// Pan is where the sound is between the speakers
+func (p *Panner) Pan() float64 {
+	return math.Float64frombits(p.pan.Load())
+}
+
+// Gain is how loud the sound is
+func (p *Panner) Gain() float64 {
+	return math.Float64frombits(p.gain.Load())
+}
+
+func (p *Panner) Read(b []byte) (int, error) {
+	// Only whole samples can be panned, so only ask for whole samples
+	if len(b) >= synthBytesPerSample {
+		b = b[:len(b)-len(b)%synthBytesPerSample]
+	}
+	n, err := p.src.Read(b)
+
+	pan, gain := p.Pan(), p.Gain()
+	left := gain * math.Min(1, 1-pan)
+	right := gain * math.Min(1, 1+pan)
+	for i := 0; i+synthBytesPerSample <= n; i += synthBytesPerSample {
+		l := int16(binary.LittleEndian.Uint16(b[i:]))
+		r := int16(binary.LittleEndian.Uint16(b[i+2:]))
+		binary.LittleEndian.PutUint16(b[i:], uint16(int16(float64(l)*left)))
+		binary.LittleEndian.PutUint16(b[i+2:], uint16(int16(float64(r)*right)))
+	}
+	return n, err
+}
+
+func (p *Panner) Seek(offset int64, whence int) (int64, error) {
+	return p.src.Seek(offset, whence)
+}
@@ -0,0 +1,34 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestPanner(t *testing.T) {
+	for _, data := range []struct {
+		Pan, Gain   float64
+		Left, Right int16
+		Reason      string
+	}{
+		{0, 1, 1000, 1000, "centre at full volume is unchanged"},
+		{-1, 1, 1000, 0, "hard left silences the right"},
+		{0.5, 1, 500, 1000, "halfway right halves the left"},
+		{0, 0.5, 500, 500, "gain makes both quieter"},
+		{2, -1, 0, 0, "out of range values are clamped"},
+	} {
+		sample := make([]byte, 4)
+		binary.LittleEndian.PutUint16(sample, 1000)
+		binary.LittleEndian.PutUint16(sample[2:], 1000)
+		p := NewPanner(bytes.NewReader(sample))
+		p.Set(data.Pan, data.Gain)
+		got, _ := io.ReadAll(p)
+		l := int16(binary.LittleEndian.Uint16(got))
+		r := int16(binary.LittleEndian.Uint16(got[2:]))
+		if l != data.Left || r != data.Right {
+			t.Errorf("panned sample was %d, %d, want %d, %d, because: %s", l, r, data.Left, data.Right, data.Reason)
+		}
+	}
+}
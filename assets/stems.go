@@ -0,0 +1,78 @@
+package assets
+
+import "time"
+
+// Stems are layers of one piece of music that play in sync, they're mixed by
+// fading each layer in or out to match what's happening
+type Stems struct {
+	Layers []*Track
+	Tempo  float64  // Beats per minute
+	queued []*Track // Stingers waiting for the next beat
+	beat   int      // Last beat that was reached
+}
+
+// NewStems loads music layers that play in sync, every file must have the same
+// length, intro and loop points
+func (m *Mixer) NewStems(tempo float64, intro, loop time.Duration, names ...string) *Stems {
+	s := &Stems{Tempo: tempo}
+	for _, name := range names {
+		s.Layers = append(s.Layers, m.Loop(name, intro, loop))
+	}
+	m.stems = append(m.stems, s)
+	return s
+}
+
+// Play starts all layers from the beginning, silent until they're faded in
+func (s *Stems) Play() {
+	for _, l := range s.Layers {
+		l.Pause()
+		l.Rewind()
+		l.Gain, l.target, l.fade = 0, 0, 0
+	}
+	for _, l := range s.Layers {
+		l.Play()
+	}
+	s.beat = 0
+}
+
+// Mix fades each layer to the given volume, in the same order as the layers
+func (s *Stems) Mix(d time.Duration, gains ...float64) {
+	for i, g := range gains {
+		if s.Layers[i].target != g {
+			s.Layers[i].FadeTo(g, d)
+		}
+	}
+}
+
+// FadeOut fades all layers to silence and stops them
+func (s *Stems) FadeOut(d time.Duration) {
+	for _, l := range s.Layers {
+		l.FadeOut(d)
+	}
+	s.queued = s.queued[:0]
+}
+
+// Sting plays a sound on the next beat so it fits in with the music
+func (s *Stems) Sting(t *Track) {
+	s.queued = append(s.queued, t)
+}
+
+// Beat is how many beats into the music the first layer is
+func (s *Stems) Beat() float64 {
+	if len(s.Layers) == 0 {
+		return 0
+	}
+	return s.Layers[0].Position().Seconds() * s.Tempo / 60
+}
+
+func (s *Stems) update() {
+	beat := int(s.Beat())
+	if beat == s.beat {
+		return
+	}
+	s.beat = beat
+	for _, t := range s.queued {
+		t.Restart()
+	}
+	s.queued = s.queued[:0]
+}
@@ -0,0 +1,89 @@
+package assets
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/sinisterstuf/freefall/ringtone"
+)
+
+// Synth volume and articulation
+const (
+	SynthVolume = 0.25                  // How loud the square wave is, from 0 to 1
+	SynthGap    = 10 * time.Millisecond // Silence at the end of each note so repeated notes are heard separately
+)
+
+// Synth is an audio stream of square-wave tones played one after another like
+// a Nokia ringtone, it's made as it's read so it takes no memory
+type Synth struct {
+	notes      []ringtone.Note
+	starts     []int64 // Sample each note starts at, with the total length at the end
+	sampleRate int
+	pos        int64 // Byte position in the stream
+}
+
+// NewSynth makes a stream of the notes as 16-bit stereo samples at sampleRate
+func NewSynth(notes []ringtone.Note, sampleRate int) *Synth {
+	s := &Synth{notes: notes, sampleRate: sampleRate}
+	var start int64
+	for _, n := range notes {
+		s.starts = append(s.starts, start)
+		start += int64(n.Length.Seconds() * float64(sampleRate))
+	}
+	s.starts = append(s.starts, start)
+	return s
+}
+
+const synthBytesPerSample = 4 // 16-bit stereo
+
+// Length is the length of the stream in bytes
+func (s *Synth) Length() int64 {
+	return s.starts[len(s.starts)-1] * synthBytesPerSample
+}
+
+func (s *Synth) Read(p []byte) (int, error) {
+	if s.pos >= s.Length() {
+		return 0, io.EOF
+	}
+	n := 0
+	note := 0
+	gap := int64(SynthGap.Seconds() * float64(s.sampleRate))
+	amp := int16(math.Round(SynthVolume * math.MaxInt16))
+	for ; n+synthBytesPerSample <= len(p) && s.pos < s.Length(); n += synthBytesPerSample {
+		i := s.pos / synthBytesPerSample
+		for s.starts[note+1] <= i {
+			note++
+		}
+		var v int16
+		if nt := s.notes[note]; nt.Pitch != ringtone.Rest && i < s.starts[note+1]-gap {
+			t := float64(i-s.starts[note]) / float64(s.sampleRate)
+			v = amp
+			if math.Mod(t*nt.Frequency(), 1) >= 0.5 {
+				v = -amp
+			}
+		}
+		p[n], p[n+1] = byte(v), byte(v>>8)
+		p[n+2], p[n+3] = byte(v), byte(v>>8)
+		s.pos += synthBytesPerSample
+	}
+	return n, nil
+}
+
+func (s *Synth) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.Length()
+	default:
+		return 0, errors.New("synth: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("synth: negative position")
+	}
+	s.pos = offset - offset%synthBytesPerSample
+	return s.pos, nil
+}
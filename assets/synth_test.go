@@ -0,0 +1,36 @@
+package assets
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sinisterstuf/freefall/ringtone"
+)
+
+func TestSynth(t *testing.T) {
+	notes := []ringtone.Note{
+		{Pitch: 69, Length: 100 * time.Millisecond},
+		{Pitch: ringtone.Rest, Length: 50 * time.Millisecond},
+	}
+	s := NewSynth(notes, 1000)
+	data, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 150*synthBytesPerSample {
+		t.Errorf("synth made %d bytes, want %d", len(data), 150*synthBytesPerSample)
+	}
+	if data[0] == 0 && data[1] == 0 {
+		t.Error("note should start with a tone")
+	}
+	for i := 100 * synthBytesPerSample; i < len(data); i++ {
+		if data[i] != 0 {
+			t.Fatalf("rest should be silent, byte %d was %d", i, data[i])
+		}
+	}
+
+	if pos, _ := s.Seek(0, io.SeekStart); pos != 0 {
+		t.Errorf("seeking to the start went to %d", pos)
+	}
+}
@@ -4,28 +4,62 @@ package game
 
 import (
 	"image"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/sinisterstuf/freefall/assets"
+	"github.com/sinisterstuf/freefall/nokia"
 )
 
 // BoxSize is based on the box sprite visual dimensions
 const BoxSize = 5
 
+// DefaultCoyote is how many ticks into a chute animation a pull still reverses
+// it straight away, rather than waiting for the animation to finish
+const DefaultCoyote = 1
+
 // Box is the player character in the game
 type Box struct {
-	Coords image.Point
-	Chute  bool
-	size   int
-	HitBox image.Rectangle
-	State  boxAnimationTags // Current animation state
-	Frame  int              // Current animation frame
-	Sprite *assets.SpriteSheet
-	Tick   int
+	Coords   Point
+	Chute    bool
+	Velocity float64 // Vertical speed, positive is downwards
+	Jolt     float64 // How far the box is jerked up by the chute opening
+	Angle    float64 // Rotation in radians, for tumbling
+	size     int
+	HitBox   image.Rectangle
+	State    boxAnimationTags // Current animation state
+	Frame    int              // Current animation frame
+	Sprite   *assets.SpriteSheet
+	Tick     int
+	Coyote   int  // Ticks into a transition during which a pull reverses it
+	pending  bool // Whether a pull is waiting for the transition to finish
+	since    int  // Ticks since the current transition started
+	puffed   bool // Whether the open chute already puffed
+	gone     bool // Whether it tumbled off the screen
+}
+
+func (b *Box) Update(ctx *UpdateContext) {
+	if ctx.Dying {
+		b.gone = !b.Tumble()
+		return
+	}
+
+	b.Drift(ctx.Wind.Strength)
+
+	if b.Chute && !b.puffed && ctx.Effects != nil {
+		ChutePuff(ctx.Effects, b)
+		Vibrate(BuzzChute)
+	}
+	b.puffed = b.Chute
+
+	b.animate()
 }
 
-func (b *Box) Update() error {
+// animate moves the chute animation on by a tick and finishes opening or
+// closing it once the animation ends
+func (b *Box) animate() {
 	b.Tick++
+	b.since++
 	b.Frame = assets.Animate(b.Frame, b.Tick, b.Sprite.Meta.FrameTags[b.State])
 	if b.Frame == b.Sprite.Meta.FrameTags[b.State].To {
 		switch b.State {
@@ -33,61 +67,155 @@ func (b *Box) Update() error {
 			b.State = boxOpen
 		case boxClosing:
 			b.State = boxClosed
+		default:
+			return
+		}
+		// Apply a pull that came in while the animation was playing
+		if b.pending {
+			b.pending = false
+			b.Pull()
 		}
 	}
-	return nil
 }
 
-func (b *Box) Draw(screen *ebiten.Image) {
-	s := b.Sprite
-	frame := s.Sprite[b.Frame]
+func (b *Box) Draw(screen *ebiten.Image, cam *Camera) {
 	op := &ebiten.DrawImageOptions{}
+	// The world scrolls past the box, so it doesn't lead with it
+	pos := cam.Screen(Point{b.Coords.X + cam.Lead.X, b.Coords.Y - b.Jolt + cam.Lead.Y})
+	drawFrame(screen, b.Sprite, b.Frame, pos, b.Angle, op)
+}
+
+// Bounds is the box's hitbox
+func (b *Box) Bounds() image.Rectangle {
+	return b.HitBox
+}
+
+// Removed tells if the box is gone after tumbling off the screen
+func (b *Box) Removed() bool {
+	return b.gone
+}
+
+func (b *Box) Layer() DrawLayer {
+	return LayerBox
+}
+
+// drawFrame draws one frame of a sprite centred on the given screen position
+// and rotated by an angle in radians
+func drawFrame(screen *ebiten.Image, s *assets.SpriteSheet, f int, pos image.Point, angle float64, op *ebiten.DrawImageOptions) {
+	op.GeoM.Concat(frameGeoM(s, f, pos, angle))
+	screen.DrawImage(frameImage(s, f), op)
+}
+
+// frameGeoM centres a frame of a sprite on a screen position and rotates it by
+// an angle in radians
+func frameGeoM(s *assets.SpriteSheet, f int, pos image.Point, angle float64) ebiten.GeoM {
+	frame := s.Sprite[f]
+	var geoM ebiten.GeoM
 
 	// Centre
-	op.GeoM.Translate(
+	geoM.Translate(
 		float64(-frame.Position.W/2),
 		float64(-frame.Position.H/2),
 	)
+	geoM.Rotate(angle)
 	// Position
-	op.GeoM.Translate(
-		float64(b.Coords.X),
-		float64(b.Coords.Y),
+	geoM.Translate(
+		float64(pos.X),
+		float64(pos.Y),
 	)
+	return geoM
+}
 
-	screen.DrawImage(
-		s.Image.SubImage(image.Rect(
-			frame.Position.X,
-			frame.Position.Y,
-			frame.Position.X+frame.Position.W,
-			frame.Position.Y+frame.Position.H,
-		)).(*ebiten.Image),
-		op,
-	)
+// frameImage is one frame of a sprite cut out of its sprite sheet
+func frameImage(s *assets.SpriteSheet, f int) *ebiten.Image {
+	frame := s.Sprite[f]
+	return s.Image.SubImage(image.Rect(
+		frame.Position.X,
+		frame.Position.Y,
+		frame.Position.X+frame.Position.W,
+		frame.Position.Y+frame.Position.H,
+	)).(*ebiten.Image)
 }
 
-func NewBox(coords image.Point, size int) *Box {
-	boxOffset := image.Pt(size/2, size/2)
-	return &Box{
+func NewBox(coords Point, size int) *Box {
+	b := &Box{
 		Coords: coords,
 		size:   size,
-		HitBox: image.Rectangle{
-			coords.Sub(boxOffset),
-			coords.Add(boxOffset),
-		},
 		Sprite: assets.LoadSprite("box"),
 		State:  boxClosed,
+		Coyote: DefaultCoyote,
+	}
+	b.updateHitBox()
+	return b
+}
+
+// updateHitBox moves the hitbox to match the box's current coordinates
+func (b *Box) updateHitBox() {
+	boxOffset := image.Pt(b.size/2, b.size/2)
+	b.HitBox = image.Rectangle{
+		b.Coords.Pt().Sub(boxOffset),
+		b.Coords.Pt().Add(boxOffset),
+	}
+}
+
+// Tumble makes the box drop and spin off the bottom of the screen, it reports
+// whether the box is still visible
+func (b *Box) Tumble() bool {
+	b.Velocity += Gravity
+	b.Coords.Y += b.Velocity
+	b.Angle += TumbleSpin
+	return b.Coords.Y < float64(nokia.GameSize.Y+b.size*2)
+}
+
+// Drift pushes the box sideways with the wind, the open chute catches more of
+// it, but the box can't be blown off the screen
+func (b *Box) Drift(wind float64) {
+	catch := FreeFallCatch
+	if b.Chute {
+		catch = ChuteCatch
 	}
+	edge := float64(b.size / 2)
+	b.Coords.X = math.Max(edge, math.Min(b.Coords.X+wind*catch, float64(nokia.GameSize.X)-edge))
+	b.updateHitBox()
 }
 
-// Move moves the player upwards
+// Pull toggles the chute, pulls during the opening or closing animation are
+// buffered until it finishes, unless they come so soon after it started that
+// the animation can just be reversed
 func (b *Box) Pull() {
-	if b.State != boxOpening && b.State != boxClosing {
-		b.Chute = !b.Chute
+	switch b.State {
+	case boxOpening, boxClosing:
+		if b.since <= b.Coyote {
+			b.reverse()
+		} else {
+			// Another pull cancels out the one already waiting
+			b.pending = !b.pending
+		}
+	default:
+		b.reverse()
 	}
-	if b.State == boxOpen {
-		b.State = boxClosing
+}
+
+// Hold opens or closes the chute to match whether the button is held, if the
+// chute is mid-animation the change waits until it finishes
+func (b *Box) Hold(open bool) {
+	switch b.State {
+	case boxOpening, boxClosing:
+		b.pending = open != b.Chute
+	default:
+		if open != b.Chute {
+			b.reverse()
+		}
 	}
-	if b.State == boxClosed {
+}
+
+// reverse flips the chute and starts animating it the other way
+func (b *Box) reverse() {
+	b.Chute = !b.Chute
+	if b.Chute {
 		b.State = boxOpening
+	} else {
+		b.State = boxClosing
 	}
+	b.since = 0
 }
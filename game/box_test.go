@@ -0,0 +1,60 @@
+package game
+
+import "testing"
+
+// settle updates the box until its chute animation has finished
+func settle(t *testing.T, b *Box) {
+	t.Helper()
+	ctx := &UpdateContext{Wind: NewWind()}
+	for i := 0; i < 20; i++ {
+		b.Update(ctx)
+		if b.State == boxOpen || b.State == boxClosed {
+			if !b.pending {
+				return
+			}
+		}
+	}
+	t.Fatalf("Chute animation didn't finish, stuck in state %d", b.State)
+}
+
+func TestBoxPull(t *testing.T) {
+	ctx := &UpdateContext{Wind: NewWind()}
+
+	for _, data := range []struct {
+		Pulls   []int // Ticks after the first pull at which more pulls happen
+		Coyote  int
+		State   boxAnimationTags // Right after the last pull
+		Pending bool             // Whether a pull is buffered right after the last pull
+		Want    bool
+		Reason  string
+	}{
+		{nil, DefaultCoyote, boxOpening, false, true, "single pull opens the chute"},
+		{[]int{0}, 0, boxClosing, false, false, "instant second pull reverses within the coyote window"},
+		{[]int{2}, 0, boxOpening, true, false, "second pull during the animation is buffered"},
+		{[]int{2, 0}, 0, boxOpening, false, true, "third pull cancels the buffered one"},
+		{[]int{1}, 1, boxClosing, false, false, "pull one tick in reverses with a one tick window"},
+		{[]int{1}, 0, boxOpening, true, false, "pull one tick in is buffered with no window"},
+	} {
+		b := NewBox(Point{10, 10}, BoxSize)
+		b.Coyote = data.Coyote
+		b.Pull()
+		for _, wait := range data.Pulls {
+			for i := 0; i < wait; i++ {
+				b.Update(ctx)
+			}
+			b.Pull()
+		}
+		if b.State != data.State || b.pending != data.Pending {
+			t.Errorf("After pulls %v state is %d with pending %v, want %d with pending %v, because: %s",
+				data.Pulls, b.State, b.pending, data.State, data.Pending, data.Reason)
+		}
+		settle(t, b)
+
+		if b.Chute != data.Want {
+			t.Errorf("Chute open is %v after pulls %v, want %v, because: %s", b.Chute, data.Pulls, data.Want, data.Reason)
+		}
+		if wantState := map[bool]boxAnimationTags{true: boxOpen, false: boxClosed}[data.Want]; b.State != wantState {
+			t.Errorf("Animation state is %d after pulls %v, want %d, because: %s", b.State, data.Pulls, wantState, data.Reason)
+		}
+	}
+}
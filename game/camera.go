@@ -0,0 +1,75 @@
+package game
+
+import (
+	"image"
+	"math"
+
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// Camera settings
+const (
+	MaxShake    = 3.0  // Furthest the screen moves when shaking, in pixels
+	TraumaDecay = 0.04 // How much trauma wears off per tick
+	FollowEase  = 0.2  // Fraction of the way to the target the camera moves per tick
+)
+
+// Camera transforms world coordinates into screen coordinates, it can follow a
+// target around and shakes when it suffers trauma
+type Camera struct {
+	Position Point           // World coordinates of the top-left of the screen
+	Target   *Point          // What to keep in the middle of the screen, if anything
+	Bounds   image.Rectangle // Where the camera may look, empty for anywhere
+	Trauma   float64         // How hard the screen is shaking, from 0 to 1
+	Lead     Point           // How far the world has scrolled since the last tick
+	shake    Point
+	tick     int
+}
+
+// NewCamera makes a camera looking at the top-left of the world
+func NewCamera() *Camera {
+	return &Camera{}
+}
+
+// AddTrauma makes the screen shake harder, up to the maximum
+func (c *Camera) AddTrauma(t float64) {
+	c.Trauma = math.Min(c.Trauma+t, 1)
+}
+
+// Update moves the camera towards its target and works out how much to shake
+func (c *Camera) Update() {
+	c.tick++
+
+	if c.Target != nil {
+		c.Position.X += (c.Target.X - float64(nokia.GameSize.X)/2 - c.Position.X) * FollowEase
+		c.Position.Y += (c.Target.Y - float64(nokia.GameSize.Y)/2 - c.Position.Y) * FollowEase
+	}
+	if !c.Bounds.Empty() {
+		c.Position.X = math.Max(float64(c.Bounds.Min.X), math.Min(c.Position.X, float64(c.Bounds.Max.X-nokia.GameSize.X)))
+		c.Position.Y = math.Max(float64(c.Bounds.Min.Y), math.Min(c.Position.Y, float64(c.Bounds.Max.Y-nokia.GameSize.Y)))
+	}
+
+	// Shake grows with the square of trauma so small knocks are subtle, the
+	// wobble is deterministic so the same run always looks the same
+	amount := MaxShake * c.Trauma * c.Trauma
+	t := float64(c.tick)
+	c.shake = Point{
+		amount * math.Sin(t*2.1) * math.Cos(t*0.7),
+		amount * math.Sin(t*1.7+1) * math.Cos(t*1.3),
+	}
+	c.Trauma = math.Max(c.Trauma-TraumaDecay, 0)
+}
+
+// Offset is how far the screen is moved by the camera shake, in whole pixels
+func (c *Camera) Offset() image.Point {
+	return c.shake.Pt()
+}
+
+// Screen converts world coordinates into screen coordinates, clamped to whole
+// pixels for the 1-bit look
+func (c *Camera) Screen(p Point) image.Point {
+	return Point{
+		p.X - c.Position.X - c.Lead.X,
+		p.Y - c.Position.Y - c.Lead.Y,
+	}.Pt().Add(c.Offset())
+}
@@ -0,0 +1,24 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"syscall/js"
+)
+
+// CopyText puts text on the clipboard through the browser, which finishes in
+// the background so failures are only logged
+func CopyText(text string) error {
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if !clipboard.Truthy() {
+		return errors.New("no clipboard available")
+	}
+	var failed js.Func
+	failed = js.FuncOf(func(this js.Value, args []js.Value) any {
+		log.Println("error copying to clipboard:", args[0].Call("toString").String())
+		failed.Release()
+		return nil
+	})
+	clipboard.Call("writeText", text).Call("catch", failed)
+	return nil
+}
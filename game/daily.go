@@ -0,0 +1,185 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"strings"
+	"time"
+)
+
+// Daily challenge, everyone gets the same run each day and one go at it
+const (
+	DailyDifficulty = DifficultyNormal // Same for everyone so results compare
+	DailyBands      = 10               // How many altitude bands the result is split into
+	dailyDate       = "2006-01-02"
+)
+
+// Daily is the player's attempt at the daily challenge
+type Daily struct {
+	Date   string  // UTC day of the attempt
+	Played bool    // Whether the scored attempt was started
+	Score  Score   // How it went
+	Replay *Replay // Recording of the scored attempt, raced against when practising
+}
+
+// Today is the daily challenge attempt for the current day, if any
+var Today Daily
+
+// DailyDate is the UTC day of t, which picks the daily challenge
+func DailyDate(t time.Time) string {
+	return t.UTC().Format(dailyDate)
+}
+
+// DailySeed is the run seed for a day, the same for everyone who plays it
+func DailySeed(date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("freefall/" + date))
+	return int64(h.Sum64())
+}
+
+// LoadDaily loads the daily challenge attempt, it's forgotten on a new day
+func LoadDaily() {
+	if err := LoadData("daily", &Today); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("error loading daily challenge:", err)
+	}
+	if Today.Replay != nil && !Today.Replay.Valid() {
+		log.Println("daily challenge run can't be played back, forgetting it")
+		Today.Replay = nil
+	}
+	today()
+}
+
+// SaveDaily saves the daily challenge attempt so it can't be retried
+func SaveDaily() {
+	if err := SaveData("daily", Today); err != nil {
+		log.Println("error saving daily challenge:", err)
+	}
+}
+
+// today makes sure Today is for the current day, clearing it when the day has
+// moved on since it was played
+func today() *Daily {
+	if date := DailyDate(time.Now()); Today.Date != date {
+		Today = Daily{Date: date}
+	}
+	return &Today
+}
+
+// Bands tells how far through each altitude band the run got: 1 if it fell all
+// the way through, 0 if it never got there, and -1 for the one it died in
+func (s Score) Bands() [DailyBands]int {
+	var bands [DailyBands]int
+	band := StartAltitude / DailyBands
+	for i := range bands {
+		switch {
+		case s.Landed || s.Metres >= float64((i+1)*band):
+			bands[i] = 1
+		case s.Metres >= float64(i*band):
+			bands[i] = -1
+		}
+	}
+	return bands
+}
+
+// Grid draws the bands with a symbol for passed, hit and not reached
+func (s Score) Grid(passed, hit, missed string) string {
+	var b strings.Builder
+	for _, band := range s.Bands() {
+		switch band {
+		case 1:
+			b.WriteString(passed)
+		case -1:
+			b.WriteString(hit)
+		default:
+			b.WriteString(missed)
+		}
+	}
+	return b.String()
+}
+
+// Share is the result of the daily challenge as text to paste to friends
+func (d Daily) Share() string {
+	end := "💥"
+	if d.Score.Landed {
+		end = "🪂"
+	}
+	return fmt.Sprintf(
+		"Freefall daily %s\n%s%s\n%d points, %d near misses",
+		d.Date, d.Score.Grid("🟩", "🟥", "⬛"), end, d.Score.Total(), d.Score.NearMisses,
+	)
+}
+
+// NewDailyScreen makes a run of today's daily challenge, only the first run of
+// the day is scored, any after that are practice
+func NewDailyScreen(input *Input) *GameScreen {
+	d := *today()
+	g := newGameScreen(input, DailySeed(d.Date), Controls, DailyDifficulty)
+	g.Daily = &d
+	g.Practice = d.Played
+	if g.Practice {
+		g.Race(d.Replay)
+	}
+	return g
+}
+
+// NewDailyMenu makes the daily challenge menu, with today's result and a way
+// to share it
+func NewDailyMenu(input *Input) *Menu {
+	status := ""
+	m := NewMenu("Daily", input, []MenuItem{
+		{
+			Label: "Play",
+			Value: func() string {
+				if today().Played {
+					return "Practice"
+				}
+				return "1 go"
+			},
+			Select: func() error {
+				status = ""
+				return startRun(ScreenDailyRun)
+			},
+		},
+		{
+			Label: "Score",
+			Value: func() string {
+				if !today().Played {
+					return "-"
+				}
+				return fmt.Sprint(Today.Score.Total())
+			},
+		},
+		{
+			Label: "Bands",
+			Value: func() string {
+				if !today().Played {
+					return "-"
+				}
+				return Today.Score.Grid("#", "x", "-")
+			},
+		},
+		{
+			Label: "Share",
+			Value: func() string { return status },
+			Select: func() error {
+				if !today().Played {
+					status = "Play first"
+				} else if err := CopyText(Today.Share()); err != nil {
+					log.Println("error copying daily result:", err)
+					status = "Failed"
+				} else {
+					status = "Copied"
+				}
+				return nil
+			},
+		},
+	})
+	m.Back = func() error {
+		status = ""
+		return &EOS{ScreenTitle}
+	}
+	return m
+}
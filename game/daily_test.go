@@ -0,0 +1,36 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailySeed(t *testing.T) {
+	day := time.Date(2024, 3, 10, 1, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	if got := DailyDate(day); got != "2024-03-09" {
+		t.Errorf("Daily date is %s, want the UTC day 2024-03-09", got)
+	}
+	if DailySeed("2024-03-09") != DailySeed("2024-03-09") {
+		t.Errorf("Same day gave different seeds")
+	}
+	if DailySeed("2024-03-09") == DailySeed("2024-03-10") {
+		t.Errorf("Different days gave the same seed")
+	}
+}
+
+func TestScoreGrid(t *testing.T) {
+	for _, data := range []struct {
+		Score  Score
+		Want   string
+		Reason string
+	}{
+		{Score{}, "x---------", "dies in the first band"},
+		{Score{Metres: 1000}, "###x------", "dies part way through a band"},
+		{Score{Metres: 2700}, "#########x", "dies in the last band"},
+		{Score{Metres: StartAltitude, Landed: true}, "##########", "landed safely"},
+	} {
+		if got := data.Score.Grid("#", "x", "-"); got != data.Want {
+			t.Errorf("Grid for %vm is %s, want %s, because: %s", data.Score.Metres, got, data.Want, data.Reason)
+		}
+	}
+}
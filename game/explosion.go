@@ -0,0 +1,66 @@
+package game
+
+//go:generate ../tools/gen_sprite_tags.sh ../assets/explosion.json explosion_anim.go explosion
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/freefall/assets"
+)
+
+// Explosion is a one-shot animation of something blowing up
+type Explosion struct {
+	Coords Point
+	State  explosionAnimationTags
+	Frame  int
+	Sprite *assets.SpriteSheet
+	Tick   int
+	Done   bool // Whether the animation has finished playing
+}
+
+func NewExplosion(coords Point) *Explosion {
+	return &Explosion{
+		Coords: coords,
+		Sprite: assets.LoadSprite("explosion"),
+		State:  explosionExplode,
+	}
+}
+
+func (e *Explosion) Update(ctx *UpdateContext) {
+	if e.Done {
+		return
+	}
+	ft := e.Sprite.Meta.FrameTags[e.State]
+	switch {
+	case e.Tick == 0:
+		// It's stepped the same tick it's spawned, so start on the first frame
+		e.Frame = ft.From
+	case e.Frame == ft.To:
+		// Only finish once the last frame has been shown too
+		e.Done = true
+	default:
+		e.Frame = assets.Animate(e.Frame, e.Tick, ft)
+	}
+	e.Tick++
+}
+
+func (e *Explosion) Draw(screen *ebiten.Image, cam *Camera) {
+	if e.Done {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	drawFrame(screen, e.Sprite, e.Frame, cam.Screen(e.Coords), 0, op)
+}
+
+func (e *Explosion) Bounds() image.Rectangle {
+	return image.Rectangle{}
+}
+
+func (e *Explosion) Removed() bool {
+	return e.Done
+}
+
+func (e *Explosion) Layer() DrawLayer {
+	return LayerEffects
+}
@@ -0,0 +1,10 @@
+package game
+
+// DO NOT EDIT
+// Generated by: ../tools/gen_sprite_tags.sh
+
+type explosionAnimationTags uint8
+
+const (
+	explosionExplode explosionAnimationTags = iota
+)
@@ -0,0 +1,27 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplosionFrames(t *testing.T) {
+	e := NewExplosion(Point{})
+	ft := e.Sprite.Meta.FrameTags[e.State]
+
+	var shown []int
+	for i := 0; i < 20 && !e.Removed(); i++ {
+		e.Update(&UpdateContext{})
+		if !e.Done {
+			shown = append(shown, e.Frame)
+		}
+	}
+
+	var want []int
+	for f := ft.From; f <= ft.To; f++ {
+		want = append(want, f)
+	}
+	if !reflect.DeepEqual(shown, want) {
+		t.Errorf("Explosion showed frames %v, want every frame once %v", shown, want)
+	}
+}
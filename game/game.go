@@ -1,32 +1,54 @@
 package game
 
 import (
+	"errors"
 	"fmt"
-	"image"
+	"io/fs"
 	"log"
+	"math"
+	"math/rand"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/sinisterstuf/freefall/assets"
 	"github.com/sinisterstuf/freefall/nokia"
+	"github.com/sinisterstuf/freefall/ringtone"
 	"github.com/tinne26/etxt"
 )
 
 const sampleRate int = 44100 // assuming "normal" sample rate
 var Context *audio.Context
+var Mixer = assets.NewMixer(nil) // Sets the volume of all music and sound effects, silent until there's audio
 
 // Using globals vs meeting deadlines
-var HighScore int = 0
+var (
+	Scores     HighScores // Best scores so far
+	LastScore  Score      // Score of the most recently finished run
+	LastRank   int        // Rank of LastScore in Scores, or -1 if it didn't place
+	LastReplay *Replay    // Recording of the most recently finished run
+	LastDaily  bool       // Whether the most recently finished run was the daily challenge
+)
 
 // Types of screens/scenes in the game
 type Screen int
 
 const (
-	ScreenTitle Screen = iota // Shows when the game first starts
-	ScreenGame                // The actual game itself
-	ScreenMax                 // How many screens there are
+	ScreenTitle      Screen = iota // Shows when the game first starts
+	ScreenGame                     // The actual game itself
+	ScreenGameOver                 // Score breakdown after a run
+	ScreenSettings                 // Options menu
+	ScreenHighScores               // Best scores so far
+	ScreenCredits                  // Who made the game
+	ScreenDaily                    // Daily challenge result and sharing
+	ScreenDailyRun                 // A run of the daily challenge
+	ScreenRace                     // A run against the ghost of the best run
+	ScreenMax                      // How many screens there are
 )
 
+// ErrQuit is returned when the player chooses to quit the game
+var ErrQuit = errors.New("game quit by player")
+
 // EOS is an End Of Screen error
 // This means the screen's logic has terminated and the controlling game should
 // switch to a different screen
@@ -44,139 +66,558 @@ type Entity interface {
 	Draw(screen *ebiten.Image)
 }
 
+// Interpolator is an Entity that can draw in between logic ticks, alpha is how
+// far it is from the last tick to the next one, from 0 to 1
+type Interpolator interface {
+	SetAlpha(alpha float64)
+}
+
+// TitleScreen shows the title until a button is pressed, then the main menu,
+// with the music and the falling box carrying on behind it
 type TitleScreen struct {
 	Background   *ebiten.Image
-	TouchIDs     *[]ebiten.TouchID
-	Music        *audio.Player
-	SFXFall      *audio.Player
+	Input        *Input
+	Music        *assets.Track
+	SFXFall      *assets.Track
 	Box          *Box
+	Camera       *Camera
 	TextRenderer *etxt.Renderer
+	Menu         *Menu
+	InMenu       bool // Whether the main menu is showing
 }
 
-func NewTitleScreen(touchIDs *[]ebiten.TouchID) *TitleScreen {
-	return &TitleScreen{
+// MusicFade is how long music takes to fade in or out
+const MusicFade = 500 * time.Millisecond
+
+func NewTitleScreen(input *Input) *TitleScreen {
+	t := &TitleScreen{
 		Background: assets.LoadImage("title-screen.png"),
-		Music:      assets.NewMusicPlayer(assets.LoadSoundFile("freefall-maintheme.ogg", sampleRate), Context),
-		SFXFall:    assets.NewSoundPlayer(assets.LoadSoundFile("sfxfall.ogg", sampleRate), Context),
-		TouchIDs:   touchIDs,
+		Music:      Mixer.Music("freefall-maintheme.ogg"),
+		SFXFall:    Mixer.Sound("sfxfall.ogg"),
+		Input:      input,
 		Box: NewBox(
-			image.Pt(nokia.GameSize.X/2, -BoxSize),
+			Point{float64(nokia.GameSize.X / 2), -BoxSize},
 			BoxSize,
 		),
+		Camera:       NewCamera(),
 		TextRenderer: NewTextRenderer(),
 	}
+	goTo := func(s Screen) func() error {
+		return func() error { return &EOS{s} }
+	}
+	t.Menu = NewMenu("Menu", input, []MenuItem{
+		{Label: "Play", Select: func() error { return startRun(ScreenGame) }},
+		{Label: "Daily Challenge", Select: goTo(ScreenDaily)},
+		{Label: "Race Ghost", Select: func() error { return startRun(ScreenRace) }},
+		{Label: "High Scores", Select: goTo(ScreenHighScores)},
+		{Label: "Settings", Select: goTo(ScreenSettings)},
+		{Label: "Credits", Select: goTo(ScreenCredits)},
+		{Label: "Quit", Select: func() error { return ErrQuit }},
+	})
+	t.Menu.Back = func() error {
+		t.InMenu = false
+		return nil
+	}
+	return t
 }
 
+// titleStarted tells if the title music and falling box have started, they
+// start again each time the title screen is come back to after a run
+var titleStarted bool
+
 func (t *TitleScreen) Update() error {
-	if !t.Music.IsPlaying() {
-		t.Music.Play()
+	if !titleStarted {
+		titleStarted = true
+		t.Music.FadeIn(MusicFade)
 		t.Box.Coords.Y = -BoxSize * 2
 	}
 
-	if t.Box.Coords.Y < nokia.GameSize.Y+BoxSize*2 {
+	if t.Box.Coords.Y < float64(nokia.GameSize.Y+BoxSize*2) {
 		t.Box.Coords.Y++
 	}
 	t.Box.Frame = assets.Animate(t.Box.Frame, t.Box.Tick, t.Box.Sprite.Meta.FrameTags[t.Box.State])
 
-	if IsMainActionButtonPressed(t.TouchIDs) {
-		t.Music.Pause()
-		t.Music.Rewind()
-		t.SFXFall.Rewind()
-		t.SFXFall.Play()
-		return &EOS{ScreenGame}
+	if t.InMenu {
+		return t.Menu.Update()
+	}
+	if t.Input.JustPressed(ActionMain) {
+		t.InMenu = true
 	}
 	return nil
 }
 
+// startRun stops the title music and starts a run on the given screen
+func startRun(next Screen) error {
+	titleStarted = false
+	Mixer.Music("freefall-maintheme.ogg").FadeOut(MusicFade)
+	Mixer.Sound("sfxfall.ogg").Restart()
+	return &EOS{next}
+}
+
 func (t *TitleScreen) Draw(screen *ebiten.Image) {
+	if t.InMenu {
+		t.Box.Draw(screen, t.Camera)
+		t.Menu.Draw(screen)
+		return
+	}
+
 	screen.DrawImage(t.Background, &ebiten.DrawImageOptions{})
-	t.Box.Draw(screen)
-	if HighScore > 0 {
+	t.Box.Draw(screen, t.Camera)
+	if Scores.Best() > 0 {
 		txt := t.TextRenderer
 		txt.SetTarget(screen)
 		txt.Draw(
-			fmt.Sprintf("Best: %dm", HighScore),
+			fmt.Sprintf("Best: %d", Scores.Best()),
 			screen.Bounds().Dx()/2,
 			screen.Bounds().Dy()/8*7,
 		)
 	}
 }
 
+// StartAltitude is how high up in metres the box is dropped from
+const StartAltitude = 3000
+
+// Death sequence timings
+const (
+	HitStopTicks = 4   // How long everything freezes when the box is hit
+	HitTrauma    = 0.9 // How hard the screen shakes when the box is hit
+	GrazeTrauma  = 0.3 // How hard the screen shakes on a near-miss
+)
+
 // GameScreen represents state for the game proper
 type GameScreen struct {
-	Box         *Box
-	Dusts       Dusts
-	Projectiles Projectiles
-	Tick        int
-	TouchIDs    *[]ebiten.TouchID
-	SFXHit      *audio.Player
+	World      *World
+	Box        *Box
+	Effects    *Emitter // Puffs, smoke and debris
+	Wind       *Wind
+	Explosion  *Explosion
+	Dying      bool // Whether the death sequence is playing
+	HitStop    int  // Ticks left to freeze for
+	Camera     *Camera
+	Score      Score
+	Tick       int
+	Input      *Input
+	Rand       *rand.Rand    // Randomness for gameplay, seeded so runs can be replayed
+	Controls   ControlScheme // How the chute is controlled during this run
+	Difficulty Difficulty    // How quickly projectiles ramp up during this run
+	Replay     *Replay       // Recording of this run
+	Playback   *ReplayPlayer // Recorded input to play instead of the player's, if any
+	Music      *assets.Stems // Calm, intense and drum layers mixed to match the action
+	Theme      *assets.Track // Main theme played as a ringtone instead of Music, if chosen
+	SFXHit     *assets.Track
+	SFXBest    *assets.Track
+	SFXLanded  *assets.Track
+	Spawner    *ProjectileSpawner
+	PassedBest bool   // Whether the score has beaten the best score yet this run
+	Daily      *Daily // The daily challenge attempt this run is for, if any
+	Practice   bool   // Whether the run doesn't count towards the scores
+	Ghost      *Ghost // An earlier run on the same seed to race against, if any
 }
 
-func (g *GameScreen) Update() error {
-	g.Tick++
+// Music layers and when they come in
+const (
+	MusicTempo         = 120 // Beats per minute
+	MusicIntro         = 4 * time.Second
+	MusicLoop          = 8 * time.Second
+	MusicCrossfade     = 300 * time.Millisecond
+	IntenseProjectiles = 8 // How many projectiles it takes for the drums to come in
+)
 
-	g.Box.Update()
+// Jingles written for the Nokia Composer
+const (
+	TuneTempo  = 240
+	TuneBest   = "8a1 8c2 8e2 4a2"
+	TuneLanded = "8c1 8e1 8g1 4c2 8- 8g1 2c2"
+)
 
-	if g.Box.Chute {
-		if g.Tick%2 == 0 {
-			g.Dusts.MoveUp()
-			g.Projectiles.MoveUp()
-		}
-	} else {
-		g.Dusts.MoveUp()
-		g.Projectiles.MoveUp()
+// Tune loads a jingle written for the Nokia Composer
+func Tune(name, tune string) *assets.Track {
+	notes, err := ringtone.ParseComposer(TuneTempo, tune)
+	if err != nil {
+		log.Fatalf("error parsing tune %s: %v\n", name, err)
 	}
+	return Mixer.Notes(name, notes)
+}
+
+var (
+	gameMusic *assets.Stems
+	themes    [DifficultyMax]*assets.Track
+)
 
-	// Difficulty
-	if g.Tick%100 == 0 {
-		if maxProjectiles < 20 {
-			maxProjectiles += 2
+// Theme loads the main theme from MIDI to play as a ringtone, faster on harder
+// difficulties
+func Theme(d Difficulty) *assets.Track {
+	if themes[d] == nil {
+		notes := assets.LoadMIDI("freefall-maintheme.mid")
+		for i := range notes {
+			notes[i].Length = time.Duration(float64(notes[i].Length) / ThemeSpeed[d])
 		}
+		themes[d] = Mixer.LoopNotes(fmt.Sprintf("freefall-maintheme.mid@%v", d), notes)
 	}
+	return themes[d]
+}
 
-	g.Dusts.Update()
-	g.Projectiles.Update(g.Tick)
+// GameMusic loads the in-game music the first time it's needed
+func GameMusic() *assets.Stems {
+	if gameMusic == nil {
+		gameMusic = Mixer.NewStems(
+			MusicTempo, MusicIntro, MusicLoop,
+			"music-calm.wav", "music-intense.wav", "music-drums.wav",
+		)
+	}
+	return gameMusic
+}
 
-	for _, p := range g.Projectiles {
-		ProjHitBox := image.Rectangle{
-			p.Coords.Pt(),
-			p.Coords.Pt().Add(image.Pt(ProjSize, ProjSize)),
+func (g *GameScreen) Update() error {
+	if g.Dying {
+		return g.updateDeath()
+	}
+
+	g.Tick++
+	if g.Tick == 1 {
+		if g.Daily != nil && !g.Practice {
+			// Starting is what uses up the day's go, so quitting doesn't get another
+			g.Daily.Played = true
+			Today = *g.Daily
+			SaveDaily()
 		}
-		if g.Box.HitBox.Overlaps(ProjHitBox) {
-			log.Printf("game over: %v hit %v", ProjHitBox, g.Box.HitBox)
-			if g.Tick > HighScore {
-				HighScore = g.Tick
-			}
-			g.SFXHit.Rewind()
-			g.SFXHit.Play()
-			return &EOS{ScreenTitle}
+		if g.Theme != nil {
+			g.Theme.FadeIn(MusicFade)
+		} else {
+			g.Music.Play()
 		}
 	}
 
+	// The box stays put on screen, everything else scrolls past as it falls
+	dist := g.Box.Fall()
+	g.Score.Fall(dist, g.Box.Chute)
+	g.Wind.Update(g.Altitude(), g.Rand)
+
+	ctx := g.context(dist)
+	g.World.Update(ctx)
+	g.Camera.Update()
+
+	if g.Altitude() == 0 {
+		log.Println("game over: landed")
+		g.Score.Landed = true
+		g.stopMusic()
+		g.SFXLanded.Restart()
+		Vibrate(BuzzLanded)
+		g.endRun()
+		return g.leave(ScreenGameOver)
+	}
+
+	if ctx.Killed != "" {
+		log.Println("game over:", ctx.Killed)
+		g.endRun()
+		g.SFXHit.Restart()
+		g.die()
+		return nil
+	}
+
 	// Movement controls
-	if IsMainActionButtonPressed(g.TouchIDs) {
-		g.Box.Pull()
+	in := g.Input.State()
+	if g.Playback != nil {
+		in = g.Playback.At(g.Tick)
+	}
+	g.Replay.Record(g.Tick, in)
+	switch g.Controls {
+	case ControlToggle:
+		if in.Pressed {
+			g.Box.Pull()
+		}
+	case ControlHold:
+		g.Box.Hold(in.Held)
+	}
+
+	g.mixMusic()
+	return nil
+}
+
+// mixMusic crossfades the music layers: calm while the chute is open, intense
+// while free-falling and drums once things get busy, with a stinger for beating
+// the best score
+func (g *GameScreen) mixMusic() {
+	best := Scores.Best()
+	if !g.PassedBest && g.Playback == nil && best > 0 && g.Score.Total() > best {
+		g.PassedBest = true
+		if g.Theme != nil {
+			g.SFXBest.Restart()
+		} else {
+			g.Music.Sting(g.SFXBest)
+		}
+	}
+
+	if g.Theme != nil {
+		return
+	}
+	calm, intense, drums := 1.0, 0.0, 0.0
+	if !g.Box.Chute {
+		calm, intense = 0, 1
+	}
+	if g.Spawner.Max >= IntenseProjectiles {
+		drums = 1
+	}
+	g.Music.Mix(MusicCrossfade, calm, intense, drums)
+}
+
+func (g *GameScreen) stopMusic() {
+	if g.Theme != nil {
+		g.Theme.FadeOut(MusicFade)
+	} else {
+		g.Music.FadeOut(MusicFade)
+	}
+}
+
+// context gathers what the actors need to know for this tick, given how far
+// the box fell
+func (g *GameScreen) context(dist float64) *UpdateContext {
+	return &UpdateContext{
+		Tick:     g.Tick,
+		Fall:     dist,
+		Altitude: g.Altitude(),
+		Dying:    g.Dying,
+		Wind:     g.Wind,
+		Box:      g.Box,
+		Score:    &g.Score,
+		Effects:  g.Effects,
+		Camera:   g.Camera,
+		Rand:     g.Rand,
+	}
+}
+
+// die starts the death sequence: everything freezes for a moment, then the
+// screen shakes while the box blows up and tumbles away
+func (g *GameScreen) die() {
+	g.Dying = true
+	g.stopMusic()
+	Vibrate(BuzzHit)
+	g.HitStop = HitStopTicks
+	g.Camera.AddTrauma(HitTrauma)
+	g.Explosion.Coords = g.Box.Coords
+	g.World.Spawn(g.Explosion)
+	g.Box.Velocity = -1 // Knocked upwards before falling away
+	Debris(g.Effects, g.Box)
+}
+
+// updateDeath plays the death sequence until the box is gone and the
+// explosion has finished, or until the player skips it
+func (g *GameScreen) updateDeath() error {
+	// Presses during the hit-stop are most likely still steering the chute,
+	// so only ones after it skip
+	if g.HitStop > 0 {
+		g.HitStop--
+		return nil
+	}
+
+	if g.Input.JustPressed(ActionMain) {
+		return g.leave(ScreenGameOver)
 	}
 
+	g.Tick++
+	g.World.Update(g.context(0))
+	g.Camera.Update()
+	if g.Box.Removed() && g.Explosion.Done {
+		return g.leave(ScreenGameOver)
+	}
 	return nil
 }
 
+// leave silences anything still making noise and moves on to the next screen
+func (g *GameScreen) leave(next Screen) error {
+	Mixer.CloseVoices()
+	return &EOS{next}
+}
+
+// SetAlpha leads the scrolling world ahead by part of a tick, so movement looks
+// smooth when drawing more often than the logic runs
+func (g *GameScreen) SetAlpha(alpha float64) {
+	g.Camera.Lead = Point{}
+	if !g.Dying {
+		g.Camera.Lead.Y = alpha * g.Box.Velocity
+	}
+}
+
 func (g *GameScreen) Draw(screen *ebiten.Image) {
-	g.Dusts.Draw(screen)
-	g.Projectiles.Draw(screen)
-	g.Box.Draw(screen)
+	g.World.Draw(screen, g.Camera)
+	g.Wind.Draw(screen)
+}
+
+// endRun records the final score of the run in the high score table, and as
+// the day's result if it was the daily challenge
+func (g *GameScreen) endRun() {
+	LastScore = g.Score
+	LastReplay = g.Replay
+	LastDaily = g.Daily != nil
+	LastRank = -1
+	if g.Playback == nil && !g.Practice {
+		LastRank = Scores.Add(g.Score)
+	}
+	if LastRank == 0 {
+		BestReplay = g.Replay
+	}
+	if g.Daily != nil && !g.Practice {
+		g.Daily.Score = g.Score
+		g.Daily.Replay = g.Replay
+		Today = *g.Daily
+		SaveDaily()
+	}
+}
+
+// LoadScores loads the high score table saved after earlier runs, and the
+// recording of the best one
+func LoadScores() {
+	if err := LoadData("scores", &Scores); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("error loading high scores:", err)
+	}
+	if err := LoadData("ghost", &BestReplay); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("error loading best run:", err)
+	}
+	if BestReplay != nil && !BestReplay.Valid() {
+		log.Println("best run can't be played back, forgetting it")
+		BestReplay = nil
+	}
+}
+
+// SaveScores saves the high score table and the best run for next time
+func SaveScores() {
+	if err := SaveData("scores", Scores); err != nil {
+		log.Println("error saving high scores:", err)
+	}
+	if err := SaveData("ghost", BestReplay); err != nil {
+		log.Println("error saving best run:", err)
+	}
+}
+
+// Altitude is how many metres up the box still is
+func (g *GameScreen) Altitude() float64 {
+	return math.Max(StartAltitude-g.Score.Metres, 0)
+}
+
+// NewGameScreen makes a new run with a random seed
+func NewGameScreen(input *Input) *GameScreen {
+	return NewSeededGameScreen(input, time.Now().UnixNano())
+}
+
+// NewReplayScreen makes a run that plays back a recorded replay
+func NewReplayScreen(input *Input, r *Replay) *GameScreen {
+	g := newGameScreen(input, r.Seed, r.Controls, r.Difficulty)
+	g.Playback = NewReplayPlayer(r)
+	return g
 }
 
-func NewGameScreen(touchIDs *[]ebiten.TouchID) *GameScreen {
-	return &GameScreen{
+// NewRaceScreen makes a run on the same seed as the best run so far, with its
+// ghost to race against, or a normal run if there isn't one yet
+func NewRaceScreen(input *Input) *GameScreen {
+	if BestReplay == nil {
+		return NewGameScreen(input)
+	}
+	g := newGameScreen(input, BestReplay.Seed, Controls, BestReplay.Difficulty)
+	g.Race(BestReplay)
+	return g
+}
+
+// Race adds the ghost of a recorded run to race against, it has to have been
+// played on the same seed and difficulty to make sense
+func (g *GameScreen) Race(r *Replay) {
+	if r == nil || r.Seed != g.Replay.Seed || r.Difficulty != g.Difficulty {
+		return
+	}
+	g.Ghost = NewGhost(r, g.Box)
+	g.World.Spawn(g.Ghost)
+}
+
+// NewSeededGameScreen makes a new run, runs with the same seed and the same
+// input always play out exactly the same way
+func NewSeededGameScreen(input *Input, seed int64) *GameScreen {
+	return newGameScreen(input, seed, Controls, Config.Difficulty)
+}
+
+func newGameScreen(input *Input, seed int64, controls ControlScheme, difficulty Difficulty) *GameScreen {
+	rng := rand.New(rand.NewSource(seed))
+	g := &GameScreen{
+		World: NewWorld(),
 		Box: NewBox(
-			image.Pt(nokia.GameSize.X/2, nokia.GameSize.Y/6),
+			Point{float64(nokia.GameSize.X / 2), float64(nokia.GameSize.Y / 6)},
 			BoxSize,
 		),
-		Dusts:       Dusts{},
-		Projectiles: Projectiles{},
-		TouchIDs:    touchIDs,
-		SFXHit:      assets.NewSoundPlayer(assets.LoadSoundFile("sfxhit.ogg", sampleRate), Context),
+		Effects:    NewEmitter(64, 16),
+		Wind:       NewWind(),
+		Explosion:  NewExplosion(Point{}),
+		Camera:     NewCamera(),
+		Input:      input,
+		Rand:       rng,
+		Controls:   controls,
+		Difficulty: difficulty,
+		Replay:     NewReplay(seed, controls, difficulty),
+		Music:      GameMusic(),
+		SFXHit:     Mixer.Sound("sfxhit.ogg"),
+		SFXBest:    Tune("best", TuneBest),
+		SFXLanded:  Tune("landed", TuneLanded),
+		Spawner:    NewProjectileSpawner(difficulty),
+	}
+
+	dust := NewEmitter(maxDusts, 1)
+	dust.Spawn = SpawnDust
+	g.World.Spawn(NewScenery(StartAltitude, rng))
+	g.World.Spawn(dust)
+	g.World.Spawn(g.Effects)
+	g.World.Spawn(g.Spawner)
+
+	if Config.Ringtone {
+		g.Theme = Theme(difficulty)
+	}
+	g.World.Spawn(g.Box)
+
+	return g
+}
+
+// GameOverScreen shows the breakdown of the last run's score
+type GameOverScreen struct {
+	Input        *Input
+	TextRenderer *etxt.Renderer
+}
+
+func NewGameOverScreen(input *Input) *GameOverScreen {
+	return &GameOverScreen{
+		Input:        input,
+		TextRenderer: NewTextRenderer(),
+	}
+}
+
+func (g *GameOverScreen) Update() error {
+	if g.Input.JustPressed(ActionMain) {
+		if LastDaily {
+			return &EOS{ScreenDaily}
+		}
+		return &EOS{ScreenTitle}
+	}
+	return nil
+}
+
+func (g *GameOverScreen) Draw(screen *ebiten.Image) {
+	s := LastScore
+	lines := []string{
+		fmt.Sprintf("Fell %dm", int(s.Metres)),
+		fmt.Sprintf("Near miss x%d +%d", s.NearMisses, s.Bonus()),
+		fmt.Sprintf("Risk +%d", int(s.Risk)),
+		fmt.Sprintf("Score %d", s.Total()),
+	}
+	if s.Landed {
+		lines[0] = fmt.Sprintf("Landed %dm", int(s.Metres))
+	}
+	switch {
+	case LastRank == 0:
+		lines = append(lines, "New best!")
+	case LastRank > 0:
+		lines = append(lines, fmt.Sprintf("Rank #%d", LastRank+1))
+	}
+
+	txt := g.TextRenderer
+	txt.SetTarget(screen)
+	lineHeight := screen.Bounds().Dy() / 6
+	for i, l := range lines {
+		txt.Draw(l, screen.Bounds().Dx()/2, lineHeight*(i+1))
 	}
 }
 
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// BestReplay is the recording of the best run so far, raced against as a ghost
+var BestReplay *Replay
+
+// Ghost is a see-through outline of the box playing back an earlier run on
+// the same seed, so the player can race against it
+type Ghost struct {
+	Box      *Box
+	Playback *ReplayPlayer
+	Metres   float64 // How far the ghost has fallen
+	Anchor   float64 // Where the live box is on screen, the ghost is drawn relative to it
+	live     float64 // How far the live box has fallen
+	removed  bool
+	canvas   *ebiten.Image
+}
+
+// NewGhost makes a ghost of a recorded run, starting alongside the live box
+func NewGhost(r *Replay, live *Box) *Ghost {
+	return &Ghost{
+		Box:      NewBox(live.Coords, live.size),
+		Playback: NewReplayPlayer(r),
+		Anchor:   live.Coords.Y,
+		canvas:   ebiten.NewImage(nokia.GameSize.X, nokia.GameSize.Y),
+	}
+}
+
+// Update falls and pulls the chute the same way as the recorded run did, it
+// only drifts with the wind as it is now since the ghost's own run may have
+// had different weather
+func (g *Ghost) Update(ctx *UpdateContext) {
+	g.live = ctx.Score.Metres
+	if g.Playback.Done(ctx.Tick - 1) {
+		if g.Metres < StartAltitude {
+			// The recorded run got hit here
+			g.removed = true
+		}
+		return
+	}
+
+	b := g.Box
+	g.Metres = math.Min(g.Metres+b.Fall(), StartAltitude)
+	b.Drift(ctx.Wind.Strength)
+	b.animate()
+
+	in := g.Playback.At(ctx.Tick)
+	switch g.Playback.Replay.Controls {
+	case ControlToggle:
+		if in.Pressed {
+			b.Pull()
+		}
+	case ControlHold:
+		b.Hold(in.Held)
+	}
+}
+
+// Draw draws the outline of the box dithered to look see-through on a 1-bit
+// screen, further down than the live box if the ghost is ahead
+func (g *Ghost) Draw(screen *ebiten.Image, cam *Camera) {
+	b := g.Box
+	pos := cam.Screen(Point{
+		b.Coords.X + cam.Lead.X,
+		g.Anchor + g.Metres - g.live - b.Jolt + cam.Lead.Y,
+	})
+	frame := frameImage(b.Sprite, b.Frame)
+	geoM := frameGeoM(b.Sprite, b.Frame, pos, b.Angle)
+
+	// Grow the sprite's shape by a pixel all round in the dark colour, then
+	// cut the shape itself back out to leave the outline
+	g.canvas.Clear()
+	var cm colorm.ColorM
+	cm.Scale(0, 0, 0, 1)
+	dr, dg, db, _ := nokia.PaletteOriginal.Dark().RGBA()
+	cm.Translate(float64(dr)/0xffff, float64(dg)/0xffff, float64(db)/0xffff, 0)
+	for _, d := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		op := &colorm.DrawImageOptions{GeoM: geoM}
+		op.GeoM.Translate(float64(d.X), float64(d.Y))
+		colorm.DrawImage(g.canvas, frame, cm, op)
+	}
+	g.canvas.DrawImage(frame, &ebiten.DrawImageOptions{GeoM: geoM, Blend: ebiten.BlendDestinationOut})
+	g.canvas.DrawImage(dither(), &ebiten.DrawImageOptions{Blend: ebiten.BlendDestinationOut})
+
+	screen.DrawImage(g.canvas, &ebiten.DrawImageOptions{})
+}
+
+// Bounds is empty, projectiles fly straight through ghosts
+func (g *Ghost) Bounds() image.Rectangle {
+	return image.Rectangle{}
+}
+
+// Removed tells if the recorded run ended before landing
+func (g *Ghost) Removed() bool {
+	return g.removed
+}
+
+func (g *Ghost) Layer() DrawLayer {
+	return LayerBox
+}
+
+var ditherMask *ebiten.Image
+
+// dither is a checkerboard the size of the screen, cutting it out of an image
+// leaves every other pixel to look half see-through
+func dither() *ebiten.Image {
+	if ditherMask == nil {
+		w, h := nokia.GameSize.X, nokia.GameSize.Y
+		pix := make([]byte, w*h*4)
+		for y := 0; y < h; y++ {
+			for x := (y % 2); x < w; x += 2 {
+				pix[(y*w+x)*4+3] = 0xff
+			}
+		}
+		ditherMask = ebiten.NewImage(w, h)
+		ditherMask.WritePixels(pix)
+	}
+	return ditherMask
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Haptics makes the device or controller vibrate, like the vibra motor of a
+// Nokia 3310
+type Haptics interface {
+	Vibrate(d time.Duration, strength float64) // Strength is from 0 to 1
+}
+
+// Vibra is where vibrations go, it does nothing until it's set up
+var Vibra Haptics = NoHaptics{}
+
+// NoHaptics never vibrates, for desktop keyboards and tests
+type NoHaptics struct{}
+
+func (NoHaptics) Vibrate(time.Duration, float64) {}
+
+// DeviceHaptics vibrates the phone or browser the game runs on
+type DeviceHaptics struct{}
+
+func (DeviceHaptics) Vibrate(d time.Duration, strength float64) {
+	ebiten.Vibrate(&ebiten.VibrateOptions{Duration: d, Magnitude: strength})
+}
+
+// GamepadHaptics rumbles every connected gamepad
+type GamepadHaptics struct {
+	Input *Input // Knows which gamepads are connected
+}
+
+func (g GamepadHaptics) Vibrate(d time.Duration, strength float64) {
+	for _, id := range g.Input.GamepadIDs {
+		ebiten.VibrateGamepad(id, &ebiten.VibrateGamepadOptions{
+			Duration:        d,
+			StrongMagnitude: strength,
+			WeakMagnitude:   strength,
+		})
+	}
+}
+
+// MultiHaptics vibrates several things at once
+type MultiHaptics []Haptics
+
+func (m MultiHaptics) Vibrate(d time.Duration, strength float64) {
+	for _, h := range m {
+		h.Vibrate(d, strength)
+	}
+}
+
+// NewHaptics picks what can vibrate on this platform: gamepads everywhere and
+// the device itself on phones and in browsers
+func NewHaptics(input *Input) Haptics {
+	h := MultiHaptics{GamepadHaptics{input}}
+	switch runtime.GOOS {
+	case "android", "ios", "js":
+		h = append(h, DeviceHaptics{})
+	}
+	return h
+}
+
+// Buzz is a vibration for something that happens in the game
+type Buzz struct {
+	Duration time.Duration
+	Strength float64
+}
+
+// Vibrations for things that happen in the game
+var (
+	BuzzChute  = Buzz{40 * time.Millisecond, 0.4}
+	BuzzGraze  = Buzz{60 * time.Millisecond, 0.6}
+	BuzzHit    = Buzz{300 * time.Millisecond, 1}
+	BuzzLanded = Buzz{150 * time.Millisecond, 0.5}
+)
+
+// Vibrate buzzes as strongly as the settings allow
+func Vibrate(b Buzz) {
+	if Config.Vibration == 0 {
+		return
+	}
+	Vibra.Vibrate(b.Duration, b.Strength*float64(Config.Vibration)/MaxVibration)
+}
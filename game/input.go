@@ -0,0 +1,156 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player can do, regardless of which button they
+// pressed to do it
+type Action uint8
+
+const (
+	ActionMain  Action = iota // The main action, toggling the chute or selecting
+	ActionUp                  // Scroll up in menus
+	ActionDown                // Scroll down in menus
+	ActionLeft                // Decrease an option
+	ActionRight               // Increase an option
+	ActionBack                // Leave a menu
+	ActionMax                 // How many actions there are
+)
+
+// Buttons are laid out like the keypad of a Nokia 3310, with 2 and 8 to
+// scroll and 5 in the middle as the main action button.
+// Fallbacks for people without a numpad:
+//   - Spacebar, Enter and arrow keys on desktop
+//   - Face buttons and D-pad on a gamepad
+//   - Tap the screen on mobile
+var actionKeys = [ActionMax][]ebiten.Key{
+	ActionMain:  {ebiten.KeyNumpad5, ebiten.KeyDigit5, ebiten.KeySpace, ebiten.KeyEnter},
+	ActionUp:    {ebiten.KeyNumpad2, ebiten.KeyDigit2, ebiten.KeyArrowUp},
+	ActionDown:  {ebiten.KeyNumpad8, ebiten.KeyDigit8, ebiten.KeyArrowDown},
+	ActionLeft:  {ebiten.KeyNumpad4, ebiten.KeyDigit4, ebiten.KeyArrowLeft},
+	ActionRight: {ebiten.KeyNumpad6, ebiten.KeyDigit6, ebiten.KeyArrowRight},
+	ActionBack:  {ebiten.KeyC, ebiten.KeyBackspace, ebiten.KeyEscape},
+}
+
+var actionButtons = [ActionMax][]ebiten.StandardGamepadButton{
+	ActionMain:  {ebiten.StandardGamepadButtonRightBottom},
+	ActionUp:    {ebiten.StandardGamepadButtonLeftTop},
+	ActionDown:  {ebiten.StandardGamepadButtonLeftBottom},
+	ActionLeft:  {ebiten.StandardGamepadButtonLeftLeft},
+	ActionRight: {ebiten.StandardGamepadButtonLeftRight},
+	ActionBack:  {ebiten.StandardGamepadButtonRightRight},
+}
+
+// Input buffers button presses between logic ticks, it's polled every frame so
+// no press is lost even though the game logic runs at a lower rate
+type Input struct {
+	TouchIDs   []ebiten.TouchID // Re-usable touch ID list
+	GamepadIDs []ebiten.GamepadID
+	Taps       []image.Point   // Where the display was tapped since the last logic tick
+	Keypad     *Keypad         // On-screen keypad that touches can press, if any
+	pressed    [ActionMax]bool // Actions pressed since the last logic tick
+	held       [ActionMax]bool // Actions held down at the last poll
+}
+
+// NewInput makes an empty input buffer
+func NewInput() *Input {
+	return &Input{}
+}
+
+// Poll checks for new presses, this should be called once per frame
+func (in *Input) Poll() {
+	in.GamepadIDs = ebiten.AppendGamepadIDs(in.GamepadIDs[:0])
+	for a := Action(0); a < ActionMax; a++ {
+		if in.isJustPressed(a) {
+			in.pressed[a] = true
+		}
+		in.held[a] = in.isHeld(a)
+	}
+
+	// Touching a key of the keypad presses it, tapping anywhere else counts
+	// as the main action
+	in.Keypad.Release()
+	in.TouchIDs = inpututil.AppendJustPressedTouchIDs(in.TouchIDs[:0])
+	for _, id := range in.TouchIDs {
+		pos := image.Pt(ebiten.TouchPosition(id))
+		if a, onKeypad := in.Keypad.At(pos); onKeypad {
+			if a != ActionMax {
+				in.pressed[a] = true
+			}
+			continue
+		}
+		in.Taps = append(in.Taps, pos)
+		in.pressed[ActionMain] = true
+	}
+	in.TouchIDs = ebiten.AppendTouchIDs(in.TouchIDs[:0])
+	for _, id := range in.TouchIDs {
+		a, onKeypad := in.Keypad.At(image.Pt(ebiten.TouchPosition(id)))
+		if !onKeypad {
+			a = ActionMain
+		}
+		if a != ActionMax {
+			in.held[a] = true
+		}
+	}
+}
+
+// JustPressed tells if an action was pressed since the last logic tick
+func (in *Input) JustPressed(a Action) bool {
+	return in.pressed[a]
+}
+
+// Held tells if an action is held down, a press that was already let go of
+// since the last logic tick counts too so quick taps aren't lost
+func (in *Input) Held(a Action) bool {
+	return in.held[a] || in.pressed[a]
+}
+
+// State is the main action's input for the current logic tick
+func (in *Input) State() InputState {
+	return InputState{
+		Pressed: in.JustPressed(ActionMain),
+		Held:    in.Held(ActionMain),
+	}
+}
+
+// Step forgets buffered presses, this should be called after each logic tick
+func (in *Input) Step() {
+	in.pressed = [ActionMax]bool{}
+	in.Taps = in.Taps[:0]
+}
+
+func (in *Input) isJustPressed(a Action) bool {
+	for _, k := range actionKeys[a] {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	for _, id := range in.GamepadIDs {
+		for _, b := range actionButtons[a] {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (in *Input) isHeld(a Action) bool {
+	for _, k := range actionKeys[a] {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	for _, id := range in.GamepadIDs {
+		for _, b := range actionButtons[a] {
+			if ebiten.IsStandardGamepadButtonPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
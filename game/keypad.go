@@ -0,0 +1,113 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/freefall/nokia"
+	"github.com/tinne26/etxt"
+)
+
+// KeypadHeight is how much room the on-screen keypad takes below the display
+const KeypadHeight = 60
+
+// Key is one button of the on-screen keypad
+type Key struct {
+	Label  string
+	Bounds image.Rectangle
+	Action Action // What pressing it does, ActionMax for nothing
+}
+
+// Keypad is an on-screen Nokia 3310 keypad for touch screens, drawn below the
+// display: C, the Navi key and the scroll keys, then the number keys
+type Keypad struct {
+	Keys         []Key
+	Bounds       image.Rectangle
+	Down         []bool // Which keys are being touched
+	TextRenderer *etxt.Renderer
+}
+
+// NewKeypad lays out a keypad with its top edge at y
+func NewKeypad(y int) *Keypad {
+	w := nokia.GameSize.X
+	k := &Keypad{
+		Bounds:       image.Rect(0, y, w, y+KeypadHeight),
+		TextRenderer: NewTextRenderer(),
+	}
+	add := func(label string, x0, y0, x1, y1 int, a Action) {
+		k.Keys = append(k.Keys, Key{label, image.Rect(x0, y+y0, x1, y+y1), a})
+	}
+
+	// Top row: C, the Navi key and the scroll key split in two
+	add("C", 0, 0, 21, 16, ActionBack)
+	add("OK", 21, 0, w-21, 16, ActionMain)
+	add("^", w-21, 0, w, 8, ActionUp)
+	add("v", w-21, 8, w, 16, ActionDown)
+
+	// Number keys, 2 4 6 8 and 5 work like they do on a real keyboard
+	labels := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "*", "0", "#"}
+	actions := map[string]Action{
+		"2": ActionUp, "4": ActionLeft, "5": ActionMain, "6": ActionRight, "8": ActionDown,
+	}
+	const rowHeight = (KeypadHeight - 16) / 4
+	for i, l := range labels {
+		a, ok := actions[l]
+		if !ok {
+			a = ActionMax
+		}
+		col, row := i%3, i/3
+		add(l, col*w/3, 16+row*rowHeight, (col+1)*w/3, 16+(row+1)*rowHeight, a)
+	}
+	k.Down = make([]bool, len(k.Keys))
+	return k
+}
+
+// At is the action of the key at a point, inside is whether the point is on
+// the keypad at all, a nil keypad has no keys
+func (k *Keypad) At(p image.Point) (a Action, inside bool) {
+	if k == nil || !p.In(k.Bounds) {
+		return ActionMax, false
+	}
+	for i, key := range k.Keys {
+		if p.In(key.Bounds) {
+			k.Down[i] = true
+			return key.Action, true
+		}
+	}
+	return ActionMax, true
+}
+
+// Release lets go of all keys, touches press them again when polled
+func (k *Keypad) Release() {
+	if k == nil {
+		return
+	}
+	for i := range k.Down {
+		k.Down[i] = false
+	}
+}
+
+func (k *Keypad) Draw(screen *ebiten.Image) {
+	dark, light := nokia.PaletteOriginal.Dark(), nokia.PaletteOriginal.Light()
+	txt := k.TextRenderer
+	txt.SetTarget(screen)
+	for i, key := range k.Keys {
+		b := key.Bounds.Inset(1)
+		x, y := float64(b.Min.X), float64(b.Min.Y)
+		w, h := float64(b.Dx()), float64(b.Dy())
+		if k.Down[i] {
+			ebitenutil.DrawRect(screen, x, y, w, h, dark)
+			txt.SetColor(light)
+		} else {
+			// Outline with the corners cut off so keys look rounded
+			ebitenutil.DrawRect(screen, x+1, y, w-2, 1, dark)
+			ebitenutil.DrawRect(screen, x+1, y+h-1, w-2, 1, dark)
+			ebitenutil.DrawRect(screen, x, y+1, 1, h-2, dark)
+			ebitenutil.DrawRect(screen, x+w-1, y+1, 1, h-2, dark)
+			txt.SetColor(dark)
+		}
+		c := b.Min.Add(b.Max).Div(2)
+		txt.Draw(key.Label, c.X, c.Y)
+	}
+}
@@ -0,0 +1,242 @@
+package game
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/freefall/nokia"
+	"github.com/tinne26/etxt"
+)
+
+// Menu layout, in pixels, like the list menus on a Nokia 3310
+const (
+	menuRows      = 3 // How many items fit on screen at once
+	menuRowHeight = 9
+	menuTop       = menuRowHeight + 1 // Items start below the title bar
+	menuScrollbar = 3                 // Width of the scroll bar on the right
+)
+
+// MenuItem is one line of a Menu
+type MenuItem struct {
+	Label  string
+	Value  func() string   // Current value shown after the label, if any
+	Change func(delta int) // Changes the value by scrolling left or right, if any
+	Select func() error    // What choosing the item does, if nil it changes the value or goes back
+}
+
+// Menu is a scrolling list of items, navigated with 2 and 8 or the arrow keys,
+// changed with 4 and 6 or left and right, chosen with 5 and left with C
+type Menu struct {
+	Title        string
+	Items        []MenuItem
+	Cursor       int          // Index of the highlighted item
+	Back         func() error // What leaving the menu does
+	Input        *Input
+	TextRenderer *etxt.Renderer
+	top          int // Index of the first item on screen
+}
+
+// NewMenu makes a menu with the first item highlighted
+func NewMenu(title string, input *Input, items []MenuItem) *Menu {
+	return &Menu{
+		Title:        title,
+		Items:        items,
+		Input:        input,
+		TextRenderer: NewTextRenderer(),
+	}
+}
+
+func (m *Menu) Update() error {
+	if len(m.Input.Taps) > 0 {
+		return m.tap(m.Input.Taps[0])
+	}
+
+	switch {
+	case m.Input.JustPressed(ActionUp):
+		m.move(-1)
+	case m.Input.JustPressed(ActionDown):
+		m.move(1)
+	case m.Input.JustPressed(ActionLeft):
+		m.change(-1)
+	case m.Input.JustPressed(ActionRight):
+		m.change(1)
+	case m.Input.JustPressed(ActionMain):
+		return m.choose()
+	case m.Input.JustPressed(ActionBack):
+		if m.Back != nil {
+			return m.Back()
+		}
+	}
+	return nil
+}
+
+// tap handles touching the screen: the title bar goes back, a row highlights
+// that item or chooses it if it was already highlighted
+func (m *Menu) tap(at image.Point) error {
+	if at.Y < menuTop {
+		if m.Back != nil {
+			return m.Back()
+		}
+		return nil
+	}
+	row := (at.Y - menuTop) / menuRowHeight
+	if row >= menuRows {
+		return m.choose() // The softkey label at the bottom
+	}
+	i := m.top + row
+	if i >= len(m.Items) {
+		return nil
+	}
+	if i == m.Cursor {
+		return m.choose()
+	}
+	m.Cursor = i
+	return nil
+}
+
+// move highlights another item, wrapping around the ends of the list
+func (m *Menu) move(delta int) {
+	n := len(m.Items)
+	m.Cursor = ((m.Cursor+delta)%n + n) % n
+	if m.Cursor < m.top {
+		m.top = m.Cursor
+	}
+	if m.Cursor >= m.top+menuRows {
+		m.top = m.Cursor - menuRows + 1
+	}
+}
+
+func (m *Menu) change(delta int) {
+	if item := m.Items[m.Cursor]; item.Change != nil {
+		item.Change(delta)
+	}
+}
+
+func (m *Menu) choose() error {
+	item := m.Items[m.Cursor]
+	switch {
+	case item.Select != nil:
+		return item.Select()
+	case item.Change != nil:
+		item.Change(1)
+	case m.Back != nil:
+		return m.Back()
+	}
+	return nil
+}
+
+func (m *Menu) Draw(screen *ebiten.Image) {
+	dark, light := nokia.PaletteOriginal.Dark(), nokia.PaletteOriginal.Light()
+	w := float64(nokia.GameSize.X)
+	txt := m.TextRenderer
+	txt.SetTarget(screen)
+
+	// Title bar
+	txt.SetColor(dark)
+	txt.SetAlign(etxt.YCenter, etxt.XCenter)
+	txt.Draw(m.Title, nokia.GameSize.X/2, menuRowHeight/2)
+	ebitenutil.DrawLine(screen, 0, menuRowHeight-0.5, w, menuRowHeight-0.5, dark)
+
+	// Items, the highlighted one inverted
+	right := nokia.GameSize.X - menuScrollbar - 2
+	for row := 0; row < menuRows && m.top+row < len(m.Items); row++ {
+		i := m.top + row
+		item := m.Items[i]
+		y := menuTop + row*menuRowHeight
+		txt.SetColor(dark)
+		if i == m.Cursor {
+			ebitenutil.DrawRect(screen, 0, float64(y), float64(right+1), menuRowHeight, dark)
+			txt.SetColor(light)
+		}
+		txt.SetAlign(etxt.YCenter, etxt.Left)
+		txt.Draw(item.Label, 1, y+menuRowHeight/2)
+		if item.Value != nil {
+			txt.SetAlign(etxt.YCenter, etxt.Right)
+			txt.Draw(item.Value(), right, y+menuRowHeight/2)
+		}
+	}
+
+	// Scroll bar showing where the highlighted item is in the list
+	trackX := w - menuScrollbar + 1
+	trackH := float64(menuRows * menuRowHeight)
+	ebitenutil.DrawLine(screen, trackX+0.5, menuTop, trackX+0.5, menuTop+trackH, dark)
+	thumbH := trackH / float64(len(m.Items))
+	ebitenutil.DrawRect(
+		screen,
+		trackX-1, menuTop+thumbH*float64(m.Cursor),
+		menuScrollbar, thumbH,
+		dark,
+	)
+
+	// Softkey label
+	label := "Back"
+	switch item := m.Items[m.Cursor]; {
+	case item.Select != nil:
+		label = "Select"
+	case item.Change != nil:
+		label = "Change"
+	}
+	txt.SetColor(dark)
+	txt.SetAlign(etxt.YCenter, etxt.XCenter)
+	txt.Draw(label, nokia.GameSize.X/2, (menuTop+menuRows*menuRowHeight+nokia.GameSize.Y)/2)
+}
+
+// highScoreColumns are the parts of a score the high score table can show,
+// switched between with left and right
+var highScoreColumns = []struct {
+	Title string
+	Value func(s Score) string
+}{
+	{"High Scores", func(s Score) string { return fmt.Sprint(s.Total()) }},
+	{"Distance", func(s Score) string { return fmt.Sprintf("%dm", int(s.Metres)) }},
+	{"Near Misses", func(s Score) string { return fmt.Sprintf("x%d +%d", s.NearMisses, s.Bonus()) }},
+	{"Risk Bonus", func(s Score) string { return fmt.Sprintf("+%d", int(s.Risk)) }},
+}
+
+// NewHighScoresScreen makes a list of the best scores so far, changing any
+// row switches every row to another part of the score
+func NewHighScoresScreen(input *Input) *Menu {
+	column := 0
+	var m *Menu
+	items := make([]MenuItem, MaxHighScores)
+	for i := range items {
+		items[i] = MenuItem{
+			Label: fmt.Sprintf("%d.", i+1),
+			Value: func() string {
+				if i >= len(Scores) {
+					return "-"
+				}
+				return highScoreColumns[column].Value(Scores[i])
+			},
+			Change: func(delta int) {
+				n := len(highScoreColumns)
+				column = ((column+delta)%n + n) % n
+				m.Title = highScoreColumns[column].Title
+			},
+		}
+	}
+	m = NewMenu(highScoreColumns[0].Title, input, items)
+	m.Back = func() error {
+		return &EOS{ScreenTitle}
+	}
+	return m
+}
+
+// NewCreditsScreen makes a list of who made the game
+func NewCreditsScreen(input *Input) *Menu {
+	credit := func(label, value string) MenuItem {
+		return MenuItem{Label: label, Value: func() string { return value }}
+	}
+	m := NewMenu("Credits", input, []MenuItem{
+		credit("Game", "Sion le Roux"),
+		credit("Font", "M. Welch"),
+		credit("Engine", "Ebitengine"),
+		credit("Jam", "Nokia 3310"),
+	})
+	m.Back = func() error {
+		return &EOS{ScreenTitle}
+	}
+	return m
+}
@@ -0,0 +1,31 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// DrawPalette draws src, which is drawn in the original palette, onto dst in
+// another palette by mapping each colour channel from one palette to the other
+func DrawPalette(dst, src *ebiten.Image, p nokia.Palette) {
+	channels := func(c color.Color) [3]float64 {
+		r, g, b, _ := c.RGBA()
+		return [3]float64{float64(r) / 0xffff, float64(g) / 0xffff, float64(b) / 0xffff}
+	}
+	fromDark, fromLight := channels(nokia.PaletteOriginal.Dark()), channels(nokia.PaletteOriginal.Light())
+	toDark, toLight := channels(p.Dark()), channels(p.Light())
+
+	var scale, shift [3]float64
+	for i := range scale {
+		scale[i] = (toLight[i] - toDark[i]) / (fromLight[i] - fromDark[i])
+		shift[i] = toDark[i] - scale[i]*fromDark[i]
+	}
+
+	var cm colorm.ColorM
+	cm.Scale(scale[0], scale[1], scale[2], 1)
+	cm.Translate(shift[0], shift[1], shift[2], 0)
+	colorm.DrawImage(dst, src, cm, &colorm.DrawImageOptions{})
+}
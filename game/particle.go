@@ -0,0 +1,197 @@
+package game
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// Particle is a single pixel of decoration like dust, smoke or debris
+type Particle struct {
+	Coords   Point
+	Velocity Point   // Pixels moved per tick
+	Gravity  float64 // Downward acceleration per tick
+	Life     int     // Ticks left to live, 0 lives until it leaves the screen
+	Colour   uint8   // Nokia palette colour index
+}
+
+// Emitter is a fixed-size pool of particles, it never allocates after being
+// made and only lets a limited number of particles be emitted per tick
+type Emitter struct {
+	particles   []Particle // Live particles are at the front of the pool
+	alive       int        // How many particles are live
+	emitted     int        // How many particles were emitted this tick
+	MaxPerFrame int        // Most particles that can be emitted per tick
+	Streak      float64    // How far and which way particles streak, 0 for none
+	Rand        *rand.Rand // Randomness for particles, seeded so runs look the same
+
+	// Spawn is called every tick before moving the particles, to emit new
+	// ones continuously
+	Spawn func(e *Emitter, ctx *UpdateContext)
+}
+
+// NewEmitter makes an emitter with a pool of size particles
+func NewEmitter(size, maxPerFrame int) *Emitter {
+	return &Emitter{
+		particles:   make([]Particle, size),
+		MaxPerFrame: maxPerFrame,
+		Rand:        rand.New(rand.NewSource(1)),
+	}
+}
+
+// Len is how many particles are currently live
+func (e *Emitter) Len() int {
+	return e.alive
+}
+
+// Emit adds a particle if there's room in the pool and this tick's budget,
+// otherwise it's silently dropped
+func (e *Emitter) Emit(p Particle) bool {
+	if e.alive == len(e.particles) || e.emitted >= e.MaxPerFrame {
+		return false
+	}
+	e.particles[e.alive] = p
+	e.alive++
+	e.emitted++
+	return true
+}
+
+// Burst emits n particles flying out in random directions from a point
+func (e *Emitter) Burst(at Point, n int, speed, gravity float64, life int, colour uint8) {
+	for i := 0; i < n; i++ {
+		angle := e.Rand.Float64() * 2 * math.Pi
+		v := speed * (0.5 + e.Rand.Float64()/2)
+		e.Emit(Particle{
+			Coords:   at,
+			Velocity: Point{math.Cos(angle) * v, math.Sin(angle) * v},
+			Gravity:  gravity,
+			Life:     life/2 + e.Rand.Intn(life/2+1),
+			Colour:   colour,
+		})
+	}
+}
+
+// Update scrolls the particles past the falling box, spawns new ones and moves
+// them all by one tick
+func (e *Emitter) Update(ctx *UpdateContext) {
+	e.MoveUp(ctx.Fall)
+	if e.Spawn != nil {
+		e.Spawn(e, ctx)
+	}
+	e.Step(ctx.Wind.Strength)
+}
+
+// Step moves all particles by one tick, blowing them sideways with the wind
+// and removing any that died or left the screen
+func (e *Emitter) Step(wind float64) {
+	e.emitted = 0
+	for i := 0; i < e.alive; {
+		p := &e.particles[i]
+		p.Velocity.Y += p.Gravity
+		p.Coords.X += p.Velocity.X + wind
+		p.Coords.Y += p.Velocity.Y
+		expired := false
+		if p.Life > 0 {
+			p.Life--
+			expired = p.Life == 0
+		}
+		if expired || !onScreen(p.Coords) {
+			// Swap the last live particle into this slot and check it next
+			e.alive--
+			e.particles[i] = e.particles[e.alive]
+			continue
+		}
+		i++
+	}
+}
+
+// MoveUp scrolls all particles up as the box falls past them
+func (e *Emitter) MoveUp(dist float64) {
+	for i := range e.particles[:e.alive] {
+		e.particles[i].Coords.Y -= dist
+	}
+}
+
+func (e *Emitter) Draw(screen *ebiten.Image, cam *Camera) {
+	streak := int(math.Round(e.Streak))
+	for _, p := range e.particles[:e.alive] {
+		pos := cam.Screen(p.Coords)
+		colour := nokia.PaletteOriginal[p.Colour]
+		ebitenutil.DrawRect(
+			screen,
+			float64(pos.X), float64(pos.Y),
+			1, 1,
+			colour,
+		)
+		// Streak trails behind the particle, upwind
+		if streak != 0 {
+			ebitenutil.DrawLine(
+				screen,
+				float64(pos.X), float64(pos.Y)+0.5,
+				float64(pos.X-streak), float64(pos.Y)+0.5,
+				colour,
+			)
+		}
+	}
+}
+
+func (e *Emitter) Bounds() image.Rectangle {
+	return image.Rectangle{}
+}
+
+func (e *Emitter) Removed() bool {
+	return false
+}
+
+func (e *Emitter) Layer() DrawLayer {
+	return LayerParticles
+}
+
+// onScreen tells if a point is on the screen or just below it, where new dust
+// appears
+func onScreen(p Point) bool {
+	return p.X >= 0 && p.X < float64(nokia.GameSize.X) &&
+		p.Y >= 0 && p.Y <= float64(nokia.GameSize.Y+1)
+}
+
+// Particle effects, emitted into the pools of GameScreen
+
+// maxDusts is how many dust particles are on screen at once
+const maxDusts = 5
+
+// SpawnDust tops up dust coming up from the bottom of the screen to give the
+// illusion of motion, streaking in the wind
+func SpawnDust(e *Emitter, ctx *UpdateContext) {
+	e.Streak = ctx.Wind.Streak()
+	if e.Len() < maxDusts {
+		e.Emit(Particle{
+			Coords: Point{float64(e.Rand.Intn(nokia.GameSize.X)), float64(nokia.GameSize.Y + 1)},
+			Colour: nokia.ColorDark,
+		})
+	}
+}
+
+// ChutePuff is a puff of air pushed out when the chute opens
+func ChutePuff(e *Emitter, b *Box) {
+	top := Point{b.Coords.X, b.Coords.Y - float64(b.size)}
+	e.Burst(top, 6, 0.8, 0, 6, nokia.ColorDark)
+}
+
+// Smoke is a trail of smoke left behind by a projectile
+func Smoke(e *Emitter, p *Projectile) {
+	e.Emit(Particle{
+		Coords:   Point{p.Coords.X - p.Velocity*float64(ProjSize+TailDist+p.Tail), p.Coords.Y + 1},
+		Velocity: Point{0, -0.1},
+		Life:     8,
+		Colour:   nokia.ColorDark,
+	})
+}
+
+// Debris is the box blowing apart when it's hit
+func Debris(e *Emitter, b *Box) {
+	e.Burst(b.Coords, 16, 1.5, Gravity, 20, nokia.ColorDark)
+}
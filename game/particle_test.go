@@ -0,0 +1,37 @@
+package game
+
+import "testing"
+
+func TestEmitterUpdate(t *testing.T) {
+	e := NewEmitter(8, 4)
+	for i := 0; i < 6; i++ {
+		e.Emit(Particle{Coords: Point{1, 1}, Life: 1 + i%2})
+	}
+	if e.Len() != 4 {
+		t.Errorf("Emitted %d particles in one tick, want at most %d", e.Len(), e.MaxPerFrame)
+	}
+
+	// Every particle with one tick of life left must be removed, even when
+	// they're next to each other in the pool
+	e.Step(0)
+	if e.Len() != 2 {
+		t.Errorf("%d particles live after update, want 2", e.Len())
+	}
+	for _, p := range e.particles[:e.alive] {
+		if p.Life != 1 {
+			t.Errorf("Particle with %d life left survived, want only ones with 1 left", p.Life)
+		}
+	}
+}
+
+func TestEmitterAllocs(t *testing.T) {
+	e := NewEmitter(64, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		e.Burst(Point{40, 20}, 16, 1, 0, 10, 1)
+		e.Step(0.5)
+		e.MoveUp(1)
+	})
+	if allocs != 0 {
+		t.Errorf("Emitting and updating particles allocated %v times per tick, want 0", allocs)
+	}
+}
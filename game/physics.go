@@ -0,0 +1,36 @@
+package game
+
+import "math"
+
+// Physics constants, all in pixels (metres) and ticks
+const (
+	Gravity       = 0.08 // Downward acceleration per tick
+	TerminalFree  = 1.2  // Top speed falling with the chute closed
+	TerminalChute = 0.5  // Top speed falling with the chute open
+	ChuteDrag     = 0.3  // Fraction of excess speed the open chute removes per tick
+	JoltScale     = 6.0  // How far the box jerks up per unit of speed lost to drag
+	JoltDecay     = 0.6  // How much of the jolt remains each tick
+	TumbleSpin    = 0.4  // Radians the box spins per tick when tumbling
+)
+
+// Fall advances the box's vertical velocity by one tick and returns how far it
+// fell, which is how far everything else should scroll past it
+func (b *Box) Fall() float64 {
+	terminal := TerminalFree
+	if b.Chute {
+		terminal = TerminalChute
+	}
+
+	if b.Velocity < terminal {
+		b.Velocity = math.Min(b.Velocity+Gravity, terminal)
+	}
+	if b.Chute && b.State != boxOpening && b.Velocity > terminal {
+		// The canopy catches air and brakes hard, tugging the box upwards
+		drag := (b.Velocity - terminal) * ChuteDrag
+		b.Velocity -= drag
+		b.Jolt += drag * JoltScale
+	}
+	b.Jolt *= JoltDecay
+
+	return b.Velocity
+}
@@ -10,7 +10,7 @@ type Point struct {
 	X, Y float64
 }
 
-func (p *Point) Pt() image.Point {
+func (p Point) Pt() image.Point {
 	return image.Pt(
 		int(math.Round(p.X)),
 		int(math.Round(p.Y)),
@@ -1,11 +1,13 @@
 package game
 
 import (
-	"math/rand"
+	"fmt"
+	"image"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/freefall/assets"
 	"github.com/sinisterstuf/freefall/nokia"
 )
 
@@ -15,111 +17,196 @@ type Projectile struct {
 	Coords   Point
 	Tail     int
 	Size     int
-	Velocity float64 // Direction and speed
-	Spacing  int     // How far away to place the next one
+	Velocity float64       // Direction and speed
+	Near     bool          // Whether it's currently passing close to the box
+	Grazed   bool          // Whether it already counted as a near-miss
+	Voice    *assets.Voice // Whooshing sound, if there's audio
+	removed  bool
 }
 
+// Projectile sounds
+const (
+	HearingRange = 80.0 // How far away in pixels projectiles can be heard from
+	PanRange     = 42.0 // How far to the side a projectile must be to only be heard on that side
+)
+
 const TailMax = 10 // Maximum length of projectile tail
 const TailDist = 1 // Distance between projectile and tail
 const ProjSize = 2 // How big a projectile's hitbox is
 
-func (p *Projectile) Update() {
-	p.Coords.X = p.Coords.X + p.Velocity
-	if p.Tail < TailMax {
-		p.Tail++
+func (p *Projectile) Update(ctx *UpdateContext) {
+	p.MoveUp(ctx.Fall)
+
+	if ctx.Tick%2 == 0 {
+		// Wind curves slow projectiles more than fast ones
+		p.Coords.X = p.Coords.X + p.Velocity + ctx.Wind.Strength*ProjWindEffect/math.Abs(p.Velocity)
+		if p.Tail < TailMax {
+			p.Tail++
+		}
+	}
+	if ctx.Tick%3 == 0 {
+		Smoke(ctx.Effects, p)
+	}
+
+	// Award near-misses once the projectile has passed by unharmed
+	near := NearMissZone(ctx.Box.HitBox).Overlaps(p.Bounds())
+	if p.Near && !near && !p.Grazed && !ctx.Dying {
+		p.Grazed = true
+		ctx.Score.NearMiss()
+		ctx.Camera.AddTrauma(GrazeTrauma)
+		Vibrate(BuzzGraze)
+	}
+	p.Near = near
+
+	if p.Coords.Y < 0 {
+		p.removed = true
 	}
+	p.listen(ctx.Box)
 }
 
-func (p *Projectile) MoveUp() {
-	p.Coords.Y--
+// listen places the projectile's sound relative to the box, panned to the side
+// it's on and quieter the further away it is
+func (p *Projectile) listen(b *Box) {
+	if p.Voice == nil {
+		return
+	}
+	if p.removed {
+		Mixer.CloseVoice(p.Voice)
+		p.Voice = nil
+		return
+	}
+	dx, dy := p.Coords.X-b.Coords.X, p.Coords.Y-b.Coords.Y
+	p.Voice.Panner.Set(dx/PanRange, 1-math.Hypot(dx, dy)/HearingRange)
 }
 
-func (p *Projectile) Draw(screen *ebiten.Image) {
+func (p *Projectile) MoveUp(dist float64) {
+	p.Coords.Y -= dist
+}
+
+func (p *Projectile) Draw(screen *ebiten.Image, cam *Camera) {
+	pos := cam.Screen(p.Coords)
+	x, y := float64(pos.X), float64(pos.Y)
 	ebitenutil.DrawRect(
 		screen,
-		float64(p.Coords.X), float64(p.Coords.Y),
+		x, y,
 		ProjSize, ProjSize,
 		nokia.PaletteOriginal.Dark(),
 	)
 	if p.Tail > 0 {
 		ebitenutil.DrawLine(
 			screen,
-			p.Coords.X-(ProjSize+TailDist)*p.Velocity, p.Coords.Y+1,
-			p.Coords.X-(ProjSize+TailDist+float64(p.Tail))*p.Velocity, p.Coords.Y+1,
+			x-(ProjSize+TailDist)*p.Velocity, y+1,
+			x-(ProjSize+TailDist+float64(p.Tail))*p.Velocity, y+1,
 			nokia.PaletteOriginal.Dark(),
 		)
 	}
 }
 
-type Projectiles []*Projectile
+func (p *Projectile) Bounds() image.Rectangle {
+	return image.Rectangle{
+		p.Coords.Pt(),
+		p.Coords.Pt().Add(image.Pt(ProjSize, ProjSize)),
+	}
+}
+
+func (p *Projectile) Removed() bool {
+	return p.removed
+}
 
-func (ps *Projectiles) Draw(screen *ebiten.Image) {
-	for _, p := range *ps {
-		p.Draw(screen)
+func (p *Projectile) Layer() DrawLayer {
+	return LayerProjectiles
+}
+
+// Collide kills the box if the projectile hits it
+func (p *Projectile) Collide(other Actor, ctx *UpdateContext) {
+	if other == ctx.Box {
+		ctx.Kill(fmt.Sprintf("%v hit %v", p.Bounds(), other.Bounds()))
 	}
 }
 
-var maxProjectiles = 2
+// Difficulty settings for spawning projectiles
+const (
+	StartProjectiles = 2   // How many projectiles can be on screen at first
+	MaxProjectiles   = 20  // Most projectiles that can ever be on screen
+	DifficultyTicks  = 100 // How often more projectiles are allowed
+	maxSpacing       = 15  // Most ticks to wait between projectiles
+)
 
-func (ps *Projectiles) Update(tick int) {
-	if len(*ps) == 0 {
-		ps.Spawn(tick)
+// ProjectileSpawner fires projectiles across the screen, more and more of
+// them as time goes on
+type ProjectileSpawner struct {
+	Max  int // How many projectiles can be on screen at the moment
+	Ramp int // How often more projectiles are allowed, in ticks
+	live []*Projectile
+	next int // Tick after which the next projectile may be fired
+}
+
+// NewProjectileSpawner makes a spawner that gets harder as quickly as the
+// difficulty says: easy starts with fewer projectiles and adds them slower,
+// hard starts with more and adds them faster
+func NewProjectileSpawner(d Difficulty) *ProjectileSpawner {
+	return &ProjectileSpawner{
+		Max:  StartProjectiles + int(d) - int(DifficultyNormal),
+		Ramp: DifficultyTicks * (2 + int(DifficultyNormal) - int(d)) / 2,
 	}
+}
 
-	if len(*ps) < maxProjectiles && tick > (*ps)[len(*ps)-1].Spacing {
-		ps.Spawn(tick)
+func (s *ProjectileSpawner) Update(ctx *UpdateContext) {
+	if ctx.Dying {
+		return
 	}
 
-	for i, p := range *ps {
-		if tick%2 == 0 {
-			p.Update()
-		}
-		if p.Coords.Y < 0 {
-			ps.Drop(i)
+	// Difficulty
+	if ctx.Tick%s.Ramp == 0 && s.Max < MaxProjectiles {
+		s.Max += 2
+	}
+
+	live := s.live[:0]
+	for _, p := range s.live {
+		if !p.Removed() {
+			live = append(live, p)
 		}
 	}
-}
+	s.live = live
 
-const maxSpacing = 15
+	if len(s.live) == 0 || (len(s.live) < s.Max && ctx.Tick > s.next) {
+		s.Spawn(ctx)
+	}
+}
 
-func (ps *Projectiles) Spawn(tick int) {
-	spawnSide := rand.Intn(2) * nokia.GameSize.X // left or right of screen
+// Spawn fires a new projectile from a random side of the screen
+func (s *ProjectileSpawner) Spawn(ctx *UpdateContext) {
+	spawnSide := ctx.Rand.Intn(2) * nokia.GameSize.X // left or right of screen
 	speedMin, speedMax := 0.8, 2.4
-	speed := speedMin + rand.Float64()*(speedMax-speedMin)
+	speed := speedMin + ctx.Rand.Float64()*(speedMax-speedMin)
 	var velocity float64
 	if spawnSide == 0 {
 		velocity = speed
 	} else {
 		velocity = -speed
 	}
-	*ps = append(*ps, &Projectile{
+	p := &Projectile{
 		Coords:   Point{float64(spawnSide), float64(nokia.GameSize.Y + 1)},
 		Size:     ProjSize,
 		Velocity: velocity,
-		Spacing:  tick + rand.Intn(maxSpacing),
-	})
-}
-
-func (ps *Projectiles) MoveUp() {
-	for _, p := range *ps {
-		p.MoveUp()
 	}
+	s.live = append(s.live, p)
+	s.next = ctx.Tick + ctx.Rand.Intn(maxSpacing)
+	p.Voice = Mixer.Voice("sfx-whoosh.wav")
+	p.listen(ctx.Box)
+	ctx.World.Spawn(p)
 }
 
-func (ps *Projectiles) Drop(i int) {
-	(*ps)[i] = nil
-	*ps = append((*ps)[:i], (*ps)[i+1:]...)
+func (s *ProjectileSpawner) Draw(screen *ebiten.Image, cam *Camera) {}
+
+func (s *ProjectileSpawner) Bounds() image.Rectangle {
+	return image.Rectangle{}
 }
 
-// Main action button is 5, like in the middle of a Nokia 3310
-// Fallbacks for people without a numpad:
-//   - Spacebar on desktop
-//   - Tap the screen on mobile
-func IsMainActionButtonPressed(TouchIDs *[]ebiten.TouchID) bool {
-	if inpututil.IsKeyJustPressed(ebiten.KeyNumpad5) ||
-		inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
-		len(*TouchIDs) > 0 {
-		return true
-	}
+func (s *ProjectileSpawner) Removed() bool {
 	return false
 }
+
+func (s *ProjectileSpawner) Layer() DrawLayer {
+	return LayerProjectiles
+}
@@ -0,0 +1,95 @@
+package game
+
+// ControlScheme is how the main action button controls the chute
+type ControlScheme uint8
+
+const (
+	ControlToggle ControlScheme = iota // Each press opens or closes the chute
+	ControlHold                        // The chute is open while the button is held
+)
+
+func (c ControlScheme) String() string {
+	if c == ControlHold {
+		return "Hold"
+	}
+	return "Toggle"
+}
+
+// Controls is the control scheme new runs are played with
+var Controls ControlScheme
+
+// InputState is the player's input during one logic tick
+type InputState struct {
+	Pressed bool `json:"p,omitempty"` // Whether the main action was just pressed
+	Held    bool `json:"h,omitempty"` // Whether the main action is held down
+}
+
+// ReplayEvent is a change of input at a given tick
+type ReplayEvent struct {
+	Tick  int        `json:"t"`
+	Input InputState `json:"i"`
+}
+
+// Replay is everything needed to play a run back exactly: the seed it was
+// played with, the control scheme, the difficulty and every change of input
+type Replay struct {
+	Seed       int64         `json:"seed"`
+	Controls   ControlScheme `json:"controls"`
+	Difficulty Difficulty    `json:"difficulty"`
+	Events     []ReplayEvent `json:"events"`
+	Ticks      int           `json:"ticks"` // How long the run lasted
+	last       InputState
+}
+
+// NewReplay makes an empty replay to record a run into
+func NewReplay(seed int64, controls ControlScheme, difficulty Difficulty) *Replay {
+	return &Replay{Seed: seed, Controls: controls, Difficulty: difficulty}
+}
+
+// Valid tells if a replay can be played back, ones loaded from a file may
+// have been changed or saved by another version of the game
+func (r *Replay) Valid() bool {
+	return r != nil && r.Controls <= ControlHold && r.Difficulty < DifficultyMax && r.Ticks >= 0
+}
+
+// Record stores the input for a tick, only presses and changes in what's held
+// are stored since nothing else is needed to play it back
+func (r *Replay) Record(tick int, in InputState) {
+	if in.Pressed || in.Held != r.last.Held {
+		r.Events = append(r.Events, ReplayEvent{tick, in})
+	}
+	r.last = in
+	r.Ticks = tick
+}
+
+// ReplayPlayer reads back the input of a recorded replay tick by tick
+type ReplayPlayer struct {
+	Replay *Replay
+	next   int // Index of the next event to play
+	held   bool
+}
+
+// NewReplayPlayer starts playing back a replay from the beginning
+func NewReplayPlayer(r *Replay) *ReplayPlayer {
+	return &ReplayPlayer{Replay: r}
+}
+
+// At is the recorded input for a tick, ticks must be asked for in order
+func (p *ReplayPlayer) At(tick int) InputState {
+	events := p.Replay.Events
+	for p.next < len(events) && events[p.next].Tick < tick {
+		p.next++
+	}
+	in := InputState{Held: p.held}
+	if p.next < len(events) && events[p.next].Tick == tick {
+		in = events[p.next].Input
+		p.next++
+	}
+	p.held = in.Held
+	return in
+}
+
+// Done tells if the whole replay has been played back
+func (p *ReplayPlayer) Done(tick int) bool {
+	return tick >= p.Replay.Ticks
+}
@@ -0,0 +1,104 @@
+package game
+
+import "testing"
+
+func TestReplayPlayback(t *testing.T) {
+	ticks := []InputState{
+		{}, {Pressed: true, Held: true}, {Held: true}, {Held: true}, {},
+		{Pressed: true, Held: true}, {}, {}, {Held: true}, {Held: true},
+	}
+
+	r := NewReplay(42, ControlHold, DifficultyNormal)
+	for tick, in := range ticks {
+		r.Record(tick, in)
+	}
+	if len(r.Events) != 5 {
+		t.Errorf("Recorded %d events, want 5 (only presses and changes)", len(r.Events))
+	}
+
+	p := NewReplayPlayer(r)
+	for tick, want := range ticks {
+		if got := p.At(tick); got != want {
+			t.Errorf("Input at tick %d played back as %+v, want %+v", tick, got, want)
+		}
+	}
+	if !p.Done(len(ticks) - 1) {
+		t.Errorf("Replay not done after its last tick")
+	}
+}
+
+func TestReplayValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		replay *Replay
+		want   bool
+	}{
+		{"recorded", NewReplay(1, ControlHold, DifficultyHard), true},
+		{"missing", nil, false},
+		{"bad difficulty", NewReplay(1, ControlToggle, DifficultyMax), false},
+		{"bad controls", NewReplay(1, ControlHold+1, DifficultyEasy), false},
+		{"bad length", &Replay{Ticks: -1}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.replay.Valid(); got != tt.want {
+			t.Errorf("%s replay valid = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestReplayRun plays a whole run without audio and checks that replaying it
+// ends up with exactly the same score
+func TestReplayRun(t *testing.T) {
+	run := func(g *GameScreen) Score {
+		for i := 0; i < 10000; i++ {
+			if i%40 == 0 && g.Playback == nil {
+				g.Input.pressed[ActionMain] = true
+			}
+			err := g.Update()
+			g.Input.Step()
+			if err != nil {
+				return g.Score
+			}
+		}
+		t.Fatal("run never ended")
+		return Score{}
+	}
+
+	g := NewSeededGameScreen(NewInput(), 7)
+	want := run(g)
+	got := run(NewReplayScreen(NewInput(), g.Replay))
+	if got != want {
+		t.Errorf("Replayed score was %+v, want %+v", got, want)
+	}
+}
+
+// TestGhostRun plays the same run again alongside its ghost and checks that
+// the ghost keeps exactly level with the box the whole way down
+func TestGhostRun(t *testing.T) {
+	step := func(g *GameScreen) error {
+		g.Input.pressed[ActionMain] = g.Tick%40 == 0
+		err := g.Update()
+		g.Input.Step()
+		return err
+	}
+
+	first := NewSeededGameScreen(NewInput(), 7)
+	for err := error(nil); err == nil && !first.Dying; {
+		err = step(first)
+	}
+
+	g := NewSeededGameScreen(NewInput(), 7)
+	g.Race(first.Replay)
+	if g.Ghost == nil {
+		t.Fatal("No ghost for a replay on the same seed")
+	}
+	for step(g) == nil && !g.Dying {
+		if g.Ghost.Metres != g.Score.Metres || g.Ghost.Box.Chute != g.Box.Chute {
+			t.Fatalf("Ghost at %vm with chute %v on tick %d, want %vm with chute %v",
+				g.Ghost.Metres, g.Ghost.Box.Chute, g.Tick, g.Score.Metres, g.Box.Chute)
+		}
+	}
+	if g.Tick < 100 {
+		t.Errorf("Run only lasted %d ticks, too short to test the ghost", g.Tick)
+	}
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/freefall/assets"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// Levels is the scenery of all levels, loaded once because it's shared by
+// every run
+var Levels []assets.Level
+
+// Scenery draws a level's background layers scrolling past at different rates
+// to give a sense of depth
+type Scenery struct {
+	Level    assets.Level
+	Offsets  []float64 // How far each repeating layer has scrolled
+	Altitude float64   // Where the anchored layers are
+}
+
+// NewScenery makes scenery for a random level, starting at the given altitude
+func NewScenery(altitude float64, rng *rand.Rand) *Scenery {
+	if Levels == nil {
+		Levels = assets.LoadLevels("scenery.json")
+	}
+	l := Levels[rng.Intn(len(Levels))]
+	return &Scenery{
+		Level:    l,
+		Offsets:  make([]float64, len(l.Layers)),
+		Altitude: altitude,
+	}
+}
+
+// Update scrolls the layers by how far the box fell to its new altitude
+func (s *Scenery) Update(ctx *UpdateContext) {
+	s.Altitude = ctx.Altitude
+	for i, l := range s.Level.Layers {
+		if l.Repeat {
+			h := float64(l.Image.Bounds().Dy())
+			s.Offsets[i] = math.Mod(s.Offsets[i]+ctx.Fall*l.Rate, h)
+		}
+	}
+}
+
+// Draw draws the layers in screen space, only moved by the camera shake
+func (s *Scenery) Draw(screen *ebiten.Image, cam *Camera) {
+	offset := cam.Offset()
+	for i, l := range s.Level.Layers {
+		h := l.Image.Bounds().Dy()
+		if l.Repeat {
+			// Tile enough copies to cover the screen
+			y := -int(s.Offsets[i] + cam.Lead.Y*l.Rate)
+			for ; y < nokia.GameSize.Y; y += h {
+				s.drawAt(screen, l, offset.Add(image.Pt(0, y)))
+			}
+			continue
+		}
+		y := l.Base - h + int(math.Round((s.Altitude-cam.Lead.Y)*l.Rate))
+		if y < nokia.GameSize.Y {
+			s.drawAt(screen, l, offset.Add(image.Pt(0, y)))
+		}
+	}
+}
+
+func (s *Scenery) drawAt(screen *ebiten.Image, l assets.Layer, pos image.Point) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(pos.X), float64(pos.Y))
+	screen.DrawImage(l.Image, op)
+}
+
+func (s *Scenery) Bounds() image.Rectangle {
+	return image.Rectangle{}
+}
+
+func (s *Scenery) Removed() bool {
+	return false
+}
+
+func (s *Scenery) Layer() DrawLayer {
+	return LayerBackground
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+const (
+	NearMissDist  = 3    // How close a projectile must pass to count as a near-miss
+	NearMissBonus = 25   // Points awarded for each near-miss
+	RiskRamp      = 45.0 // Ticks of free-fall needed to gain one extra multiplier
+	MaxRisk       = 3.0  // Highest multiplier free-fall can earn
+)
+
+// Score is the breakdown of points earned during one run
+type Score struct {
+	Metres     float64 // Distance fallen, counting only actual descent
+	NearMisses int     // Projectiles that passed close without hitting
+	Risk       float64 // Extra points earned by the free-fall multiplier
+	FreeFall   int     // Ticks spent falling with the chute closed
+	Landed     bool    // Whether the box made it all the way to the ground
+	streak     int     // Ticks of free-fall since the chute was last open
+}
+
+// Fall accrues points for descending dist metres, with the chute either open
+// or closed, and ramps up the risk multiplier while free-falling
+func (s *Score) Fall(dist float64, chute bool) {
+	if chute {
+		s.streak = 0
+	} else {
+		s.streak++
+		s.FreeFall++
+	}
+	s.Metres += dist
+	s.Risk += dist * (s.Multiplier() - 1)
+}
+
+// Multiplier is the current risk multiplier from continuous free-fall
+func (s *Score) Multiplier() float64 {
+	return math.Min(1+float64(s.streak)/RiskRamp, MaxRisk)
+}
+
+// NearMiss awards the bonus for one projectile passing close by
+func (s *Score) NearMiss() {
+	s.NearMisses++
+}
+
+// Bonus is the number of points earned from near-misses
+func (s Score) Bonus() int {
+	return s.NearMisses * NearMissBonus
+}
+
+// Total is the final number of points the score is worth
+func (s Score) Total() int {
+	return int(s.Metres) + int(s.Risk) + s.Bonus()
+}
+
+// NearMissZone is the area around a hitbox in which passing projectiles count
+// as near-misses
+func NearMissZone(hitBox image.Rectangle) image.Rectangle {
+	return hitBox.Inset(-NearMissDist)
+}
+
+// MaxHighScores is how many scores the high score table keeps
+const MaxHighScores = 5
+
+// HighScores is a table of the best scores, best first
+type HighScores []Score
+
+// Add places a score in the table and returns its rank, or -1 if it wasn't
+// good enough to make it in
+func (hs *HighScores) Add(s Score) int {
+	rank := sort.Search(len(*hs), func(i int) bool {
+		return (*hs)[i].Total() < s.Total()
+	})
+	if rank >= MaxHighScores {
+		return -1
+	}
+	*hs = append(*hs, Score{})
+	copy((*hs)[rank+1:], (*hs)[rank:])
+	(*hs)[rank] = s
+	if len(*hs) > MaxHighScores {
+		*hs = (*hs)[:MaxHighScores]
+	}
+	return rank
+}
+
+// Best is the top score in the table, or 0 if it's empty
+func (hs HighScores) Best() int {
+	if len(hs) == 0 {
+		return 0
+	}
+	return hs[0].Total()
+}
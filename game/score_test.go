@@ -0,0 +1,47 @@
+package game
+
+import "testing"
+
+func TestHighScoresAdd(t *testing.T) {
+	var hs HighScores
+	for _, data := range []struct {
+		Metres float64
+		Want   int
+		Reason string
+	}{
+		{10, 0, "first score is always the best"},
+		{5, 1, "lower score goes below"},
+		{20, 0, "higher score goes on top"},
+		{7, 2, "middle score slots in between"},
+		{1, 4, "fills the table"},
+		{0, -1, "doesn't place when the table is full"},
+		{8, 2, "pushes the lowest score out"},
+	} {
+		if got := hs.Add(Score{Metres: data.Metres}); got != data.Want {
+			t.Errorf("Adding %vm ranked %d, want %d, because: %s", data.Metres, got, data.Want, data.Reason)
+		}
+	}
+	if len(hs) != MaxHighScores {
+		t.Errorf("Table has %d scores, want %d", len(hs), MaxHighScores)
+	}
+	if hs[len(hs)-1].Metres != 5 {
+		t.Errorf("Lowest score is %vm, want 5m", hs[len(hs)-1].Metres)
+	}
+}
+
+func TestScoreFall(t *testing.T) {
+	var s Score
+	for i := 0; i < int(RiskRamp); i++ {
+		s.Fall(1, false)
+	}
+	if s.Multiplier() != 2 {
+		t.Errorf("Multiplier after %v ticks of free-fall is %v, want 2", RiskRamp, s.Multiplier())
+	}
+	s.Fall(0.5, true)
+	if s.Multiplier() != 1 {
+		t.Errorf("Multiplier after opening the chute is %v, want 1", s.Multiplier())
+	}
+	if s.Metres != RiskRamp+0.5 {
+		t.Errorf("Fell %vm, want %vm", s.Metres, RiskRamp+0.5)
+	}
+}
@@ -0,0 +1,290 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"log"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/freefall/assets"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// Difficulty changes how quickly projectiles get more numerous
+type Difficulty uint8
+
+const (
+	DifficultyEasy Difficulty = iota
+	DifficultyNormal
+	DifficultyHard
+	DifficultyMax // How many difficulties there are
+)
+
+func (d Difficulty) String() string {
+	return [...]string{"Easy", "Normal", "Hard"}[d]
+}
+
+// ThemeSpeed is how fast the ringtone theme plays at each difficulty
+var ThemeSpeed = [DifficultyMax]float64{0.85, 1, 1.2}
+
+// Limits of settings values
+const (
+	MaxVolume      = 10
+	MaxVibration   = 3
+	MaxWindowScale = 20
+)
+
+// Settings are the player's options, saved between plays
+type Settings struct {
+	Volume      int           `json:"volume"`      // Master volume from 0 to MaxVolume
+	MusicVolume int           `json:"musicVolume"` // From 0 to MaxVolume
+	SFXVolume   int           `json:"sfxVolume"`   // From 0 to MaxVolume
+	Muted       bool          `json:"muted"`
+	Ringtone    bool          `json:"ringtone"` // Play the main theme from MIDI in game instead of the layered music
+	Palette     int           `json:"palette"`  // Index into nokia.Palettes
+	Controls    ControlScheme `json:"controls"`
+	Vibration   int           `json:"vibration"` // Strength from 0 for off to MaxVibration
+	Difficulty  Difficulty    `json:"difficulty"`
+	WindowScale int           `json:"windowScale"`
+	Fullscreen  bool          `json:"fullscreen"`
+	Keypad      bool          `json:"keypad"` // Whether to show the on-screen keypad
+}
+
+// Config is the settings currently in use
+var Config = DefaultSettings()
+
+// DefaultSettings are used when nothing has been saved yet
+func DefaultSettings() Settings {
+	return Settings{
+		Volume:      MaxVolume,
+		MusicVolume: MaxVolume,
+		SFXVolume:   MaxVolume,
+		Difficulty:  DifficultyNormal,
+		Vibration:   MaxVibration,
+		WindowScale: 10,
+		Keypad:      runtime.GOOS == "android" || runtime.GOOS == "ios",
+	}
+}
+
+// LoadSettings loads saved settings, falling back to the defaults for
+// anything missing or invalid
+func LoadSettings() Settings {
+	s := DefaultSettings()
+	if err := LoadData("settings", &s); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("error loading settings, using defaults:", err)
+		s = DefaultSettings()
+	}
+	s.clamp()
+	return s
+}
+
+// Flags are one-off changes to the settings from the command line, they're in
+// effect while the game runs but aren't saved
+type Flags struct {
+	Hold   bool // Hold to keep the chute open
+	Mute   bool // Start muted
+	Keypad bool // Show the on-screen keypad
+}
+
+var (
+	flags     Flags    // Flags in effect
+	unflagged Settings // The settings as they were before the flags
+)
+
+// SetFlags changes the settings to match the flags
+func (s *Settings) SetFlags(f Flags) {
+	flags, unflagged = f, *s
+	if f.Hold {
+		s.Controls = ControlHold
+	}
+	if f.Mute {
+		s.Muted = true
+	}
+	if f.Keypad {
+		s.Keypad = true
+	}
+}
+
+// unflag puts back what the flags changed so they're not saved, once the
+// player changes a setting themselves the flag for it no longer counts
+func (s *Settings) unflag() {
+	if flags.Hold {
+		if s.Controls == ControlHold {
+			s.Controls = unflagged.Controls
+		} else {
+			flags.Hold = false
+		}
+	}
+	if flags.Mute {
+		if s.Muted {
+			s.Muted = unflagged.Muted
+		} else {
+			flags.Mute = false
+		}
+	}
+	if flags.Keypad {
+		if s.Keypad {
+			s.Keypad = unflagged.Keypad
+		} else {
+			flags.Keypad = false
+		}
+	}
+}
+
+// Save saves the settings for next time, without any flags
+func (s Settings) Save() {
+	s.unflag()
+	if err := SaveData("settings", s); err != nil {
+		log.Println("error saving settings:", err)
+	}
+}
+
+// clamp keeps every setting within its valid range
+func (s *Settings) clamp() {
+	s.Volume = clamp(s.Volume, 0, MaxVolume)
+	s.MusicVolume = clamp(s.MusicVolume, 0, MaxVolume)
+	s.SFXVolume = clamp(s.SFXVolume, 0, MaxVolume)
+	s.Palette = clamp(s.Palette, 0, len(nokia.Palettes)-1)
+	s.Controls = ControlScheme(clamp(int(s.Controls), 0, int(ControlHold)))
+	s.Difficulty = Difficulty(clamp(int(s.Difficulty), 0, int(DifficultyMax)-1))
+	s.WindowScale = clamp(s.WindowScale, 1, MaxWindowScale)
+	s.Vibration = clamp(s.Vibration, 0, MaxVibration)
+}
+
+// Apply puts the settings into effect
+func (s Settings) Apply() {
+	Controls = s.Controls
+	size := s.ScreenSize()
+	ebiten.SetWindowSize(size.X*s.WindowScale, size.Y*s.WindowScale)
+	ebiten.SetFullscreen(s.Fullscreen)
+	Mixer.Master = assets.Channel{Volume: volume(s.Volume), Muted: s.Muted}
+	Mixer.Buses[assets.BusMusic].Volume = volume(s.MusicVolume)
+	Mixer.Buses[assets.BusSFX].Volume = volume(s.SFXVolume)
+}
+
+// ScreenSize is the size of the display plus the keypad if it's shown
+func (s Settings) ScreenSize() image.Point {
+	if s.Keypad {
+		return nokia.GameSize.Add(image.Pt(0, KeypadHeight))
+	}
+	return nokia.GameSize
+}
+
+// volume turns a volume setting into a fraction for the mixer
+func volume(v int) float64 {
+	return float64(v) / MaxVolume
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// NewSettingsScreen makes a menu for changing the settings, they're saved when
+// leaving it
+func NewSettingsScreen(input *Input) *Menu {
+	// change makes a function that changes a setting and applies it
+	change := func(f func(delta int)) func(delta int) {
+		return func(delta int) {
+			f(delta)
+			Config.clamp()
+			Config.Apply()
+		}
+	}
+	wrap := func(v, delta, n int) int {
+		return ((v+delta)%n + n) % n
+	}
+
+	m := NewMenu("Settings", input, []MenuItem{
+		{
+			Label:  "Volume",
+			Value:  func() string { return fmt.Sprint(Config.Volume) },
+			Change: change(func(d int) { Config.Volume += d }),
+		},
+		{
+			Label:  "Music",
+			Value:  func() string { return fmt.Sprint(Config.MusicVolume) },
+			Change: change(func(d int) { Config.MusicVolume += d }),
+		},
+		{
+			Label:  "Sounds",
+			Value:  func() string { return fmt.Sprint(Config.SFXVolume) },
+			Change: change(func(d int) { Config.SFXVolume += d }),
+		},
+		{
+			Label:  "Mute",
+			Value:  func() string { return onOff(Config.Muted) },
+			Change: change(func(int) { Config.Muted = !Config.Muted }),
+		},
+		{
+			Label:  "Palette",
+			Value:  func() string { return nokia.PaletteNames[Config.Palette] },
+			Change: change(func(d int) { Config.Palette = wrap(Config.Palette, d, len(nokia.Palettes)) }),
+		},
+		{
+			Label: "Theme",
+			Value: func() string {
+				if Config.Ringtone {
+					return "Ringtone"
+				}
+				return "Layered"
+			},
+			Change: change(func(int) { Config.Ringtone = !Config.Ringtone }),
+		},
+		{
+			Label:  "Controls",
+			Value:  func() string { return Config.Controls.String() },
+			Change: change(func(d int) { Config.Controls = ControlScheme(wrap(int(Config.Controls), d, 2)) }),
+		},
+		{
+			Label: "Vibration",
+			Value: func() string {
+				return [...]string{"Off", "Low", "Medium", "High"}[Config.Vibration]
+			},
+			Change: change(func(d int) {
+				Config.Vibration = wrap(Config.Vibration, d, MaxVibration+1)
+				Vibrate(BuzzChute) // So the player can feel the difference
+			}),
+		},
+		{
+			Label:  "Difficulty",
+			Value:  func() string { return Config.Difficulty.String() },
+			Change: change(func(d int) { Config.Difficulty = Difficulty(wrap(int(Config.Difficulty), d, int(DifficultyMax))) }),
+		},
+		{
+			Label:  "Scale",
+			Value:  func() string { return fmt.Sprintf("x%d", Config.WindowScale) },
+			Change: change(func(d int) { Config.WindowScale += d }),
+		},
+		{
+			Label:  "Keypad",
+			Value:  func() string { return onOff(Config.Keypad) },
+			Change: change(func(int) { Config.Keypad = !Config.Keypad }),
+		},
+		{
+			Label:  "Fullscreen",
+			Value:  func() string { return onOff(Config.Fullscreen) },
+			Change: change(func(int) { Config.Fullscreen = !Config.Fullscreen }),
+		},
+	})
+	m.Back = func() error {
+		Config.Save()
+		return &EOS{ScreenTitle}
+	}
+	return m
+}
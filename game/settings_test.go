@@ -0,0 +1,33 @@
+package game
+
+import "testing"
+
+func TestSettingsFlags(t *testing.T) {
+	s := DefaultSettings()
+	s.Keypad = false
+	s.SetFlags(Flags{Mute: true, Keypad: true})
+	if !s.Muted || !s.Keypad {
+		t.Fatalf("Flags didn't change the settings: %+v", s)
+	}
+
+	saved := s
+	saved.unflag()
+	if saved.Muted || saved.Keypad {
+		t.Errorf("Flags would be saved: %+v", saved)
+	}
+
+	// Unmuting and muting again is the player's own choice, so it's kept
+	s.Muted = false
+	saved = s
+	saved.unflag()
+	s.Muted = true
+	saved = s
+	saved.unflag()
+	if !saved.Muted {
+		t.Errorf("Muting after the flag was changed wasn't saved")
+	}
+	if saved.Keypad {
+		t.Errorf("Untouched keypad flag would be saved")
+	}
+	flags = Flags{}
+}
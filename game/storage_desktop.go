@@ -0,0 +1,47 @@
+//go:build !js
+
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// saveDir is where save data is kept, in the user's config directory
+func saveDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "freefall"), nil
+}
+
+// LoadData reads saved data with the given name into v
+func LoadData(name string, v any) error {
+	dir, err := saveDir()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SaveData writes v to saved data with the given name
+func SaveData(name string, v any) error {
+	dir, err := saveDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".json"), data, 0o644)
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"syscall/js"
+)
+
+// storageKey is where data with a name is kept in the browser's local storage
+func storageKey(name string) string {
+	return "freefall/" + name
+}
+
+// localStorage gets the browser's local storage, which can be missing or
+// blocked, e.g. in private browsing
+func localStorage() (storage js.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("local storage unavailable: %v", r)
+		}
+	}()
+	storage = js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return js.Value{}, fmt.Errorf("local storage unavailable")
+	}
+	return storage, nil
+}
+
+// LoadData reads saved data with the given name into v
+func LoadData(name string, v any) (err error) {
+	storage, err := localStorage()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("reading %s: %v", name, r)
+		}
+	}()
+	item := storage.Call("getItem", storageKey(name))
+	if item.IsNull() {
+		return fs.ErrNotExist
+	}
+	return json.Unmarshal([]byte(item.String()), v)
+}
+
+// SaveData writes v to saved data with the given name
+func SaveData(name string, v any) (err error) {
+	storage, err := localStorage()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("writing %s: %v", name, r)
+		}
+	}()
+	storage.Call("setItem", storageKey(name), string(data))
+	return nil
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/freefall/nokia"
+)
+
+// Wind settings, strengths are in pixels per tick
+const (
+	WindMin        = 0.1  // Strongest gust at ground level
+	WindMax        = 0.5  // Strongest gust at the starting altitude
+	WindWarning    = 20   // Ticks of warning before a gust arrives
+	GustMin        = 20   // Shortest a gust lasts in ticks
+	GustMax        = 60   // Longest a gust lasts in ticks
+	CalmMin        = 30   // Shortest calm between gusts in ticks
+	CalmMax        = 90   // Longest calm between gusts in ticks
+	WindRamp       = 0.05 // Fraction of the way to the target strength per tick
+	ChuteCatch     = 1.0  // How much of the wind pushes the box with the chute open
+	FreeFallCatch  = 0.3  // How much of the wind pushes the box with the chute closed
+	ProjWindEffect = 0.5  // How much the wind curves a projectile moving at speed 1
+)
+
+// Wind phases
+type windPhase uint8
+
+const (
+	windCalm windPhase = iota
+	windWarning
+	windGusting
+)
+
+// Wind pushes things sideways in periodic gusts which are telegraphed by the
+// dust streaking in the direction the gust is about to blow
+type Wind struct {
+	Strength float64 // Current push, positive is to the right
+	Gust     float64 // Strength of the upcoming or current gust
+	phase    windPhase
+	timer    int // Ticks left in the current phase
+}
+
+// NewWind makes a calm wind that will start gusting after a while
+func NewWind() *Wind {
+	return &Wind{timer: CalmMin}
+}
+
+// Update advances the wind by one tick, gusts are stronger the higher up the
+// altitude (in metres) is
+func (w *Wind) Update(altitude float64, rng *rand.Rand) {
+	w.timer--
+	if w.timer <= 0 {
+		switch w.phase {
+		case windCalm:
+			maxGust := WindMin + (WindMax-WindMin)*math.Min(altitude/StartAltitude, 1)
+			w.Gust = maxGust * (0.5 + rng.Float64()/2)
+			if rng.Intn(2) == 0 {
+				w.Gust = -w.Gust
+			}
+			w.phase, w.timer = windWarning, WindWarning
+		case windWarning:
+			w.phase, w.timer = windGusting, GustMin+rng.Intn(GustMax-GustMin)
+		case windGusting:
+			w.phase, w.timer = windCalm, CalmMin+rng.Intn(CalmMax-CalmMin)
+		}
+	}
+
+	target := 0.0
+	if w.phase == windGusting {
+		target = w.Gust
+	}
+	w.Strength += (target - w.Strength) * WindRamp
+}
+
+// Warning tells if a gust is about to arrive
+func (w *Wind) Warning() bool {
+	return w.phase == windWarning
+}
+
+// Streak is how long and in which direction dust should streak
+func (w *Wind) Streak() float64 {
+	if w.Warning() {
+		// Grows as the gust gets closer
+		return math.Copysign(float64(WindWarning-w.timer)/WindWarning*3, w.Gust)
+	}
+	return w.Strength * 6
+}
+
+// Draw shows the wind direction and strength as an arrow in the corner of the
+// screen, blinking the upcoming direction when a gust is about to arrive
+func (w *Wind) Draw(screen *ebiten.Image) {
+	const maxLen = 8
+	anchor := Point{float64(nokia.GameSize.X - maxLen - 2), 2}
+
+	strength := w.Strength
+	if w.Warning() {
+		if w.timer/2%2 == 0 {
+			return
+		}
+		strength = w.Gust
+	}
+
+	length := int(math.Min(math.Abs(strength)/WindMax*maxLen, maxLen))
+	if length < 1 {
+		return
+	}
+
+	// Arrow shaft grows out from the side the wind is coming from
+	dir, x := 1, int(anchor.X)
+	if strength < 0 {
+		dir, x = -1, x+maxLen
+	}
+	colour := nokia.PaletteOriginal.Dark()
+	y := int(anchor.Y)
+	for i := 0; i < length; i++ {
+		ebitenutil.DrawRect(screen, float64(x+dir*i), float64(y), 1, 1, colour)
+	}
+	if length >= 3 {
+		tip := x + dir*(length-2)
+		ebitenutil.DrawRect(screen, float64(tip), float64(y-1), 1, 1, colour)
+		ebitenutil.DrawRect(screen, float64(tip), float64(y+1), 1, 1, colour)
+	}
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"image"
+	"math/rand"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawLayer decides what gets drawn on top of what, higher layers are drawn
+// over lower ones
+type DrawLayer int
+
+const (
+	LayerBackground  DrawLayer = iota // Scenery far behind everything
+	LayerParticles                    // Dust, smoke and puffs
+	LayerProjectiles                  // Things flying across the screen
+	LayerBox                          // The player
+	LayerEffects                      // Explosions on top of everything
+)
+
+// Actor is anything that lives in the World, it's updated and drawn every
+// frame until it flags itself as removed
+type Actor interface {
+	Update(ctx *UpdateContext)
+	Draw(screen *ebiten.Image, cam *Camera)
+	Bounds() image.Rectangle // Collision box in world coordinates, empty if it can't be hit
+	Removed() bool           // Whether the world should drop it
+	Layer() DrawLayer
+}
+
+// Collider is an Actor that reacts to touching other actors
+type Collider interface {
+	Actor
+	Collide(other Actor, ctx *UpdateContext)
+}
+
+// UpdateContext is everything an Actor might need to know about the current
+// tick of the game
+type UpdateContext struct {
+	Tick     int
+	Fall     float64 // How far the box fell this tick, so how far to scroll
+	Altitude float64 // How high up the box is in metres
+	Dying    bool    // Whether the death sequence is playing
+	Wind     *Wind
+	Box      *Box
+	Score    *Score
+	Effects  *Emitter // Shared pool for one-off particle effects
+	Camera   *Camera
+	World    *World
+	Rand     *rand.Rand // Randomness for gameplay, seeded per run
+	Killed   string     // Why the box died this tick, if it did
+}
+
+// Kill ends the run, the reason is just for logging
+func (ctx *UpdateContext) Kill(reason string) {
+	if ctx.Killed == "" {
+		ctx.Killed = reason
+	}
+}
+
+// World holds all the actors in the game and takes care of updating, drawing
+// and colliding them
+type World struct {
+	actors  []Actor
+	spawned []Actor // Added during an update, joins the world after it
+	drawn   []Actor // Re-usable slice for sorting by draw layer
+	grid    spatialGrid
+}
+
+// NewWorld makes an empty world
+func NewWorld() *World {
+	return &World{grid: spatialGrid{}}
+}
+
+// Spawn adds an actor to the world, it's safe to call during an update, in
+// which case the actor only starts updating on the next tick
+func (w *World) Spawn(a Actor) {
+	w.spawned = append(w.spawned, a)
+}
+
+// Update updates all actors, collides them and then drops removed ones
+func (w *World) Update(ctx *UpdateContext) {
+	ctx.World = w
+	w.actors = append(w.actors, w.spawned...)
+	w.spawned = w.spawned[:0]
+
+	for _, a := range w.actors {
+		if !a.Removed() {
+			a.Update(ctx)
+		}
+	}
+
+	w.grid.reset()
+	for _, a := range w.actors {
+		if !a.Removed() {
+			w.grid.insert(a)
+		}
+	}
+	for _, a := range w.actors {
+		c, ok := a.(Collider)
+		if !ok || c.Removed() {
+			continue
+		}
+		w.Query(c.Bounds(), func(other Actor) {
+			if other != a {
+				c.Collide(other, ctx)
+			}
+		})
+	}
+
+	// Compact in place so removal never skips or reallocates
+	live := w.actors[:0]
+	for _, a := range w.actors {
+		if !a.Removed() {
+			live = append(live, a)
+		}
+	}
+	for i := len(live); i < len(w.actors); i++ {
+		w.actors[i] = nil
+	}
+	w.actors = live
+}
+
+// Query calls fn for every actor whose bounds overlap r, as of the last update
+func (w *World) Query(r image.Rectangle, fn func(Actor)) {
+	if r.Empty() {
+		return
+	}
+	w.grid.query(r, func(a Actor) {
+		if !a.Removed() && a.Bounds().Overlaps(r) {
+			fn(a)
+		}
+	})
+}
+
+// Draw draws all actors from the lowest layer to the highest, actors in the
+// same layer are drawn in the order they were spawned
+func (w *World) Draw(screen *ebiten.Image, cam *Camera) {
+	w.drawn = append(w.drawn[:0], w.actors...)
+	sort.SliceStable(w.drawn, func(i, j int) bool {
+		return w.drawn[i].Layer() < w.drawn[j].Layer()
+	})
+	for _, a := range w.drawn {
+		if !a.Removed() {
+			a.Draw(screen, cam)
+		}
+	}
+}
+
+// gridCell is the size of the squares the spatial grid divides the world into
+const gridCell = 16
+
+// spatialGrid buckets actors by which grid squares their bounds touch, so
+// collision checks only look at actors nearby
+type spatialGrid map[image.Point][]Actor
+
+func (g spatialGrid) reset() {
+	for k, v := range g {
+		g[k] = v[:0]
+	}
+}
+
+func (g spatialGrid) insert(a Actor) {
+	b := a.Bounds()
+	if b.Empty() {
+		return
+	}
+	forCells(b, func(cell image.Point) {
+		g[cell] = append(g[cell], a)
+	})
+}
+
+func (g spatialGrid) query(r image.Rectangle, fn func(Actor)) {
+	seen := map[Actor]bool{}
+	forCells(r, func(cell image.Point) {
+		for _, a := range g[cell] {
+			if !seen[a] {
+				seen[a] = true
+				fn(a)
+			}
+		}
+	})
+}
+
+// forCells calls fn for every grid cell a rectangle touches
+func forCells(r image.Rectangle, fn func(image.Point)) {
+	min := image.Pt(floorDiv(r.Min.X, gridCell), floorDiv(r.Min.Y, gridCell))
+	max := image.Pt(floorDiv(r.Max.X-1, gridCell), floorDiv(r.Max.Y-1, gridCell))
+	for y := min.Y; y <= max.Y; y++ {
+		for x := min.X; x <= max.X; x++ {
+			fn(image.Pt(x, y))
+		}
+	}
+}
+
+// floorDiv divides rounding towards negative infinity so cells left of and
+// above the origin don't get squashed into cell 0
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testActor is a square that removes itself after a number of updates and
+// counts what it collided with
+type testActor struct {
+	rect    image.Rectangle
+	life    int
+	updates int
+	hits    int
+}
+
+func (a *testActor) Update(ctx *UpdateContext) {
+	a.updates++
+	a.life--
+}
+
+func (a *testActor) Draw(screen *ebiten.Image, cam *Camera) {}
+func (a *testActor) Bounds() image.Rectangle                { return a.rect }
+func (a *testActor) Removed() bool                          { return a.life <= 0 }
+func (a *testActor) Layer() DrawLayer                       { return LayerBox }
+
+func (a *testActor) Collide(other Actor, ctx *UpdateContext) {
+	a.hits++
+}
+
+func TestWorldUpdate(t *testing.T) {
+	w := NewWorld()
+	short := &testActor{rect: image.Rect(0, 0, 4, 4), life: 2}
+	long := &testActor{rect: image.Rect(2, 2, 6, 6), life: 3}
+	far := &testActor{rect: image.Rect(60, 30, 64, 34), life: 5}
+	for _, a := range []*testActor{short, long, far} {
+		w.Spawn(a)
+	}
+
+	w.Update(&UpdateContext{})
+	if long.hits != 1 {
+		t.Errorf("Overlapping actor collided %d times, want 1", long.hits)
+	}
+	if far.hits != 0 {
+		t.Errorf("Distant actor collided %d times, want 0", far.hits)
+	}
+
+	w.Update(&UpdateContext{})
+	if len(w.actors) != 2 {
+		t.Errorf("World has %d actors after removal, want 2", len(w.actors))
+	}
+	w.Update(&UpdateContext{})
+	if short.updates != 2 || long.updates != 3 {
+		t.Errorf("Actors updated %d and %d times, want 2 and 3", short.updates, long.updates)
+	}
+
+	var found []Actor
+	w.Query(image.Rect(50, 20, 70, 40), func(a Actor) {
+		found = append(found, a)
+	})
+	if len(found) != 1 || found[0] != far {
+		t.Errorf("Query found %v, want only the distant actor", found)
+	}
+}
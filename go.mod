@@ -3,6 +3,7 @@ module github.com/sinisterstuf/freefall
 go 1.23
 
 require (
+	github.com/ebitengine/oto/v3 v3.3.1
 	github.com/hajimehoshi/ebiten/v2 v2.8.1
 	github.com/tinne26/etxt v0.0.8
 )
@@ -10,7 +11,6 @@ require (
 require (
 	github.com/ebitengine/gomobile v0.0.0-20241001034212-22433622d8a5 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
-	github.com/ebitengine/oto/v3 v3.3.1 // indirect
 	github.com/ebitengine/purego v0.8.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
 	github.com/jfreymuth/oggvorbis v1.0.5 // indirect
@@ -6,91 +6,37 @@ package main
 
 import (
 	"errors"
-	"image"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/freefall/app"
 	"github.com/sinisterstuf/freefall/game"
-	"github.com/sinisterstuf/freefall/nokia"
 )
 
-const sampleRate int = 44100 // assuming "normal" sample rate
-
 func main() {
-	windowScale := 10
-	ebiten.SetWindowSize(nokia.GameSize.X*windowScale, nokia.GameSize.Y*windowScale)
-	ebiten.SetWindowTitle("Freefall")
-	ebiten.SetTPS(15)
-
-	TouchIDs := []ebiten.TouchID{}
-	game.Context = audio.NewContext(sampleRate)
-
-	g := &Game{
-		Size: nokia.GameSize,
-		Screens: []game.Entity{
-			game.NewTitleScreen(&TouchIDs),
-			game.NewGameScreen(&TouchIDs),
-		},
-		TouchIDs: &TouchIDs,
-	}
-
-	if err := ebiten.RunGame(g); err != nil {
-		log.Fatal(err)
-	}
-}
-
-// Game represents the main Ebitengine Game state that coordinates screens
-type Game struct {
-	Size     image.Point       // Physical game dimensions
-	TouchIDs *[]ebiten.TouchID // Re-usable touch ID list
-	Screens  []game.Entity     // A slice of all possible game screens
-	Screen   game.Screen       // The current screen
-}
-
-// Layout is hardcoded for now, may be made dynamic in future
-func (g *Game) Layout(outsideWidth int, outsideHeight int) (screenWidth int, screenHeight int) {
-	return g.Size.X, g.Size.Y
-}
-
-// Update calculates game logic
-func (g *Game) Update() error {
-
-	// This should only be needed once per update
-	*g.TouchIDs = inpututil.AppendJustPressedTouchIDs((*g.TouchIDs)[:0])
-
-	// Pressing Q any time quits immediately
-	if ebiten.IsKeyPressed(ebiten.KeyQ) {
-		return errors.New("game quit by player")
+	opts := app.DefaultOptions
+	flag.IntVar(&opts.Rate, "rate", opts.Rate, "game logic ticks per second")
+	flag.BoolVar(&opts.Interpolate, "interpolate", false, "smooth movement in between logic ticks")
+	flag.BoolVar(&opts.Mute, "mute", false, "start with the sound muted")
+	flag.BoolVar(&opts.NoAudio, "no-audio", false, "run without any audio, for machines with no sound")
+	flag.BoolVar(&opts.Hold, "hold", false, "hold the button to keep the chute open instead of toggling it")
+	flag.BoolVar(&opts.Keypad, "keypad", false, "show the on-screen keypad like on mobile")
+	flag.Parse()
+	if opts.Rate <= 0 {
+		fmt.Fprintln(flag.CommandLine.Output(), "invalid value for flag -rate: must be more than 0")
+		flag.Usage()
+		os.Exit(2)
 	}
 
-	// Pressing F toggles full-screen
-	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
-		if ebiten.IsFullscreen() {
-			ebiten.SetFullscreen(false)
-		} else {
-			ebiten.SetFullscreen(true)
-		}
+	err := ebiten.RunGame(app.New(opts))
+	if errors.Is(err, game.ErrQuit) {
+		return
 	}
-
-	err := g.Screens[g.Screen].Update()
-	var EOS *game.EOS
-	if errors.As(err, &EOS) {
-		next := EOS.NextScreen
-		log.Println("resetting game screen to:", next)
-		// Should be better logic here but right now it's just really important
-		// to reset this
-		g.Screens[game.ScreenGame] = game.NewGameScreen(g.TouchIDs)
-		g.Screen = next
-		return nil
+	if err != nil {
+		app.ReportError(err)
+		log.Fatal(err)
 	}
-
-	return err
-}
-
-// Draw draws the game screen by one frame
-func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(nokia.PaletteOriginal.Light())
-	g.Screens[g.Screen].Draw(screen)
 }
@@ -0,0 +1,299 @@
+// Package midi reads Standard MIDI Files and turns them into monophonic tunes
+// that a single square-wave channel can play, like a phone ringtone
+package midi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/sinisterstuf/freefall/ringtone"
+)
+
+// defaultTempo is the length of a quarter note until the file says otherwise,
+// 120 beats per minute
+const defaultTempo = 500 * time.Millisecond
+
+// Event is a note starting or stopping
+type Event struct {
+	Tick    int64 // Time since the start of the track in ticks
+	Channel int
+	Key     int  // MIDI note number
+	On      bool // Whether the note starts, a note on with no velocity is off
+}
+
+// TempoChange sets the length of a quarter note from a tick onwards
+type TempoChange struct {
+	Tick    int64
+	Quarter time.Duration
+}
+
+// File is the note and tempo data of a Standard MIDI File
+type File struct {
+	Format   int
+	Division int // Ticks per quarter note, or negative for SMTPE timing
+	Tracks   [][]Event
+	Tempo    []TempoChange // In order of tick
+}
+
+// Parse reads a Standard MIDI File, only notes and tempo are kept
+func Parse(r io.Reader) (*File, error) {
+	br := bufio.NewReader(r)
+	id, data, err := readChunk(br)
+	if err != nil {
+		return nil, err
+	}
+	if id != "MThd" || len(data) < 6 {
+		return nil, errors.New("midi: not a standard MIDI file")
+	}
+	f := &File{
+		Format:   int(binary.BigEndian.Uint16(data[0:])),
+		Division: int(int16(binary.BigEndian.Uint16(data[4:]))),
+	}
+	// SMPTE time is negative, so its frames per second can't be 0 but its
+	// ticks per frame can
+	if f.Division == 0 || f.Division < 0 && f.Division&0xff == 0 {
+		return nil, errors.New("midi: zero time division")
+	}
+	tracks := int(binary.BigEndian.Uint16(data[2:]))
+
+	for len(f.Tracks) < tracks {
+		id, data, err := readChunk(br)
+		if err != nil {
+			return nil, err
+		}
+		if id != "MTrk" {
+			continue // Unknown chunks must be skipped
+		}
+		events, err := f.parseTrack(data)
+		if err != nil {
+			return nil, fmt.Errorf("midi: track %d: %w", len(f.Tracks), err)
+		}
+		f.Tracks = append(f.Tracks, events)
+	}
+	sort.SliceStable(f.Tempo, func(i, j int) bool {
+		return f.Tempo[i].Tick < f.Tempo[j].Tick
+	})
+	return f, nil
+}
+
+func readChunk(r io.Reader) (id string, data []byte, err error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", nil, fmt.Errorf("midi: reading chunk: %w", err)
+	}
+	// Only read as much as there really is, rather than trusting the length
+	// enough to allocate it all up front
+	size := int64(binary.BigEndian.Uint32(header[4:]))
+	data, err = io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return "", nil, fmt.Errorf("midi: reading chunk: %w", err)
+	}
+	if int64(len(data)) < size {
+		return "", nil, fmt.Errorf("midi: reading chunk: %w", io.ErrUnexpectedEOF)
+	}
+	return string(header[:4]), data, nil
+}
+
+// track reads the bytes of a track chunk
+type track struct {
+	data []byte
+	pos  int
+}
+
+var errShort = errors.New("unexpected end of track")
+
+func (t *track) byte() (byte, error) {
+	if t.pos >= len(t.data) {
+		return 0, errShort
+	}
+	t.pos++
+	return t.data[t.pos-1], nil
+}
+
+// varInt reads a variable-length quantity, 7 bits per byte
+func (t *track) varInt() (int64, error) {
+	var n int64
+	for i := 0; i < 4; i++ {
+		b, err := t.byte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<7 | int64(b&0x7f)
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return 0, errors.New("variable-length number too long")
+}
+
+func (t *track) skip(n int64) error {
+	if int64(len(t.data)-t.pos) < n {
+		return errShort
+	}
+	t.pos += int(n)
+	return nil
+}
+
+func (f *File) parseTrack(data []byte) ([]Event, error) {
+	t := &track{data: data}
+	var events []Event
+	var tick int64
+	var status byte
+	for t.pos < len(t.data) {
+		delta, err := t.varInt()
+		if err != nil {
+			return nil, err
+		}
+		tick += delta
+
+		b, err := t.byte()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case b == 0xff: // Meta event
+			kind, err := t.byte()
+			if err != nil {
+				return nil, err
+			}
+			n, err := t.varInt()
+			if err != nil {
+				return nil, err
+			}
+			if kind == 0x51 && n == 3 && t.pos+3 <= len(t.data) {
+				d := t.data[t.pos:]
+				us := int64(d[0])<<16 | int64(d[1])<<8 | int64(d[2])
+				f.Tempo = append(f.Tempo, TempoChange{tick, time.Duration(us) * time.Microsecond})
+			}
+			if err := t.skip(n); err != nil {
+				return nil, err
+			}
+			if kind == 0x2f {
+				return events, nil // End of track
+			}
+			continue
+		case b == 0xf0 || b == 0xf7: // System exclusive
+			n, err := t.varInt()
+			if err != nil {
+				return nil, err
+			}
+			if err := t.skip(n); err != nil {
+				return nil, err
+			}
+			continue
+		case b&0x80 != 0:
+			status = b
+		case status == 0:
+			return nil, errors.New("data byte without a status")
+		default:
+			t.pos-- // Running status, b is the first data byte
+		}
+
+		// Channel messages, everything but notes is skipped
+		var args [2]byte
+		size := 2
+		if kind := status & 0xf0; kind == 0xc0 || kind == 0xd0 {
+			size = 1
+		}
+		for i := 0; i < size; i++ {
+			if args[i], err = t.byte(); err != nil {
+				return nil, err
+			}
+		}
+		switch status & 0xf0 {
+		case 0x90, 0x80:
+			events = append(events, Event{
+				Tick:    tick,
+				Channel: int(status & 0x0f),
+				Key:     int(args[0]),
+				On:      status&0xf0 == 0x90 && args[1] > 0,
+			})
+		}
+	}
+	return events, nil
+}
+
+// Duration is how long from the start of the file until a tick
+func (f *File) Duration(tick int64) time.Duration {
+	if f.Division < 0 {
+		// SMPTE: frames per second in the high byte, ticks per frame in the low
+		fps := int64(-(f.Division >> 8))
+		perFrame := int64(f.Division & 0xff)
+		return time.Duration(tick) * time.Second / time.Duration(fps*perFrame)
+	}
+
+	var d time.Duration
+	from, quarter := int64(0), defaultTempo
+	for _, c := range f.Tempo {
+		if c.Tick >= tick {
+			break
+		}
+		d += ticks(c.Tick-from, quarter, f.Division)
+		from, quarter = c.Tick, c.Quarter
+	}
+	return d + ticks(tick-from, quarter, f.Division)
+}
+
+func ticks(n int64, quarter time.Duration, division int) time.Duration {
+	return time.Duration(n) * quarter / time.Duration(division)
+}
+
+// Monophonic flattens the notes of a track into a tune that plays one note
+// at a time, when notes overlap the highest one is heard. A track below 0
+// flattens all tracks together.
+func (f *File) Monophonic(track int) []ringtone.Note {
+	var events []Event
+	if track < 0 {
+		for _, t := range f.Tracks {
+			events = append(events, t...)
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Tick < events[j].Tick
+		})
+	} else if track < len(f.Tracks) {
+		events = f.Tracks[track]
+	}
+
+	var notes []ringtone.Note
+	held := map[[2]int]int{} // How many times each channel and key is held down
+	sounding, since := ringtone.Rest, int64(0)
+	retrigger := false // Whether the sounding note is played again
+	for i, e := range events {
+		k := [2]int{e.Channel, e.Key}
+		if e.On {
+			held[k]++
+		} else if held[k] > 0 {
+			held[k]--
+		}
+		if held[k] == 0 {
+			delete(held, k)
+		}
+		retrigger = retrigger || e.On && e.Key == sounding
+		// Wait until every event at this tick has happened
+		if i+1 < len(events) && events[i+1].Tick == e.Tick {
+			continue
+		}
+
+		highest := ringtone.Rest
+		for k := range held {
+			highest = max(highest, k[1])
+		}
+		if highest == sounding && !retrigger {
+			continue
+		}
+		if e.Tick > since {
+			notes = append(notes, ringtone.Note{Pitch: sounding, Length: f.Duration(e.Tick) - f.Duration(since)})
+		}
+		sounding, since, retrigger = highest, e.Tick, false
+	}
+	// A tune that starts with silence starts at the first note instead
+	if len(notes) > 0 && notes[0].Pitch == ringtone.Rest {
+		notes = notes[1:]
+	}
+	return notes
+}
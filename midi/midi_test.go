@@ -0,0 +1,99 @@
+package midi
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sinisterstuf/freefall/ringtone"
+)
+
+// smf builds a format 0 file with one track at 96 ticks per quarter note
+func smf(track ...byte) []byte {
+	data := []byte{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 0, 0, 1, 0, 96}
+	data = append(data, 'M', 'T', 'r', 'k', 0, 0, 0, byte(len(track)))
+	return append(data, track...)
+}
+
+// smpte builds an empty file with an SMPTE time division
+func smpte(fps, perFrame byte) []byte {
+	data := smf(0, 0xff, 0x2f, 0)
+	data[12], data[13] = fps, perFrame
+	return data
+}
+
+func TestMonophonic(t *testing.T) {
+	for _, data := range []struct {
+		Track  []byte
+		Want   []ringtone.Note
+		Reason string
+	}{
+		{
+			[]byte{0, 0x90, 60, 100, 96, 0x80, 60, 0},
+			[]ringtone.Note{{Pitch: 60, Length: 500 * time.Millisecond}},
+			"a quarter note at the default tempo",
+		},
+		{
+			[]byte{0, 0x90, 60, 100, 48, 64, 100, 48, 0x80, 64, 0, 0, 0x80, 60, 0},
+			[]ringtone.Note{{Pitch: 60, Length: 250 * time.Millisecond}, {Pitch: 64, Length: 250 * time.Millisecond}},
+			"highest note is heard over a held one, running status",
+		},
+		{
+			[]byte{0, 0x90, 67, 100, 0, 60, 100, 48, 67, 0, 48, 60, 0},
+			[]ringtone.Note{{Pitch: 67, Length: 250 * time.Millisecond}, {Pitch: 60, Length: 250 * time.Millisecond}},
+			"lower note comes back when the higher one stops",
+		},
+		{
+			[]byte{0, 0xff, 0x51, 3, 0x0f, 0x42, 0x40, 96, 0x90, 60, 100, 96, 60, 0, 0, 0xff, 0x2f, 0},
+			[]ringtone.Note{{Pitch: 60, Length: time.Second}},
+			"tempo change and leading silence dropped",
+		},
+		{
+			[]byte{0, 0x90, 60, 100, 48, 60, 0, 48, 60, 100, 48, 60, 0},
+			[]ringtone.Note{
+				{Pitch: 60, Length: 250 * time.Millisecond},
+				{Pitch: ringtone.Rest, Length: 250 * time.Millisecond},
+				{Pitch: 60, Length: 250 * time.Millisecond},
+			},
+			"gaps between notes are rests",
+		},
+		{
+			[]byte{0, 0xc0, 5, 0, 0xf0, 1, 0xf7, 0, 0x90, 60, 100, 96, 60, 0},
+			[]ringtone.Note{{Pitch: 60, Length: 500 * time.Millisecond}},
+			"other messages are skipped",
+		},
+	} {
+		f, err := Parse(bytes.NewReader(smf(data.Track...)))
+		if err != nil {
+			t.Errorf("Parse failed: %v, because: %s", err, data.Reason)
+			continue
+		}
+		if got := f.Monophonic(0); !reflect.DeepEqual(got, data.Want) {
+			t.Errorf("Monophonic was %v, want %v, because: %s", got, data.Want, data.Reason)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []struct {
+		File   []byte
+		Reason string
+	}{
+		{[]byte("RIFF"), "too short"},
+		{[]byte{'R', 'I', 'F', 'F', 0, 0, 0, 6, 0, 0, 0, 1, 0, 96}, "not a MIDI header"},
+		{smf(0, 60, 100), "data without a status"},
+		{smf(0, 0x90, 60), "truncated note"},
+		{smf(0xff, 0xff, 0xff, 0xff, 0x7f), "delta time too long"},
+		{smpte(0xe7, 0), "no ticks per SMPTE frame"},
+		{[]byte{'M', 'T', 'h', 'd', 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 1, 0, 96}, "chunk longer than the file"},
+	} {
+		if _, err := Parse(bytes.NewReader(data.File)); err == nil {
+			t.Errorf("Parse should fail, because: %s", data.Reason)
+		}
+	}
+
+	if _, err := Parse(bytes.NewReader(smpte(0xe7, 40))); err != nil {
+		t.Errorf("Parse failed on 25fps SMPTE time: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package mobile is the entry point of the Android and iOS builds, it's built
+// into a library with ebitenmobile:
+//
+//	ebitenmobile bind -target android -javapkg com.sinisterstuf.freefall -o freefall.aar ./mobile
+//	ebitenmobile bind -target ios -o Freefall.xcframework ./mobile
+package mobile
+
+import (
+	ebitenmobile "github.com/hajimehoshi/ebiten/v2/mobile"
+	"github.com/sinisterstuf/freefall/app"
+)
+
+func init() {
+	ebitenmobile.SetGame(app.New(app.DefaultOptions))
+}
+
+// Dummy is exported so that ebitenmobile has something to bind, it does
+// nothing
+func Dummy() {}
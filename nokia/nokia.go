@@ -40,6 +40,12 @@ var (
 	}
 )
 
+// Palettes are all the palettes the game can be played in
+var Palettes = []Palette{PaletteOriginal, PaletteHarsh, PaletteGray}
+
+// PaletteNames are the names of Palettes, in the same order
+var PaletteNames = []string{"Original", "Harsh", "Gray"}
+
 // Palette wraps color.Palette with convenience methods for the Nokia greenish
 // 2-bit+transparency color palettes
 type Palette color.Palette
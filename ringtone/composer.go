@@ -0,0 +1,71 @@
+package ringtone
+
+import "time"
+
+// composerOctave is the octave of middle C that the Composer's octave 1 is,
+// so "a1" is 880Hz
+const composerOctave = 5
+
+// ParseComposer reads a tune in the format of the Nokia Composer, e.g.
+// "16c2 8#d2 4- 8.a1", at a tempo in beats per minute. Each note is a
+// duration, optional dot, optional sharp, note name and octave from 1 to 3, or
+// "-" for a rest.
+func ParseComposer(bpm int, s string) ([]Note, error) {
+	if bpm <= 0 {
+		return nil, &SyntaxError{Token: s, Msg: "bad tempo"}
+	}
+	whole := wholeNote(bpm)
+	var notes []Note
+	for _, t := range split(s, 0, " \t\r\n") {
+		n, err := parseComposerNote(t, whole)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// parseComposerNote reads one note, e.g. "8.#c2" or "4-"
+func parseComposerNote(t token, whole time.Duration) (Note, error) {
+	s := &scanner{token: t}
+	d, ok := s.number()
+	if !ok || !validDuration(d) {
+		return Note{}, s.fail("bad duration")
+	}
+	n := Note{Pitch: Rest, Length: whole / time.Duration(d)}
+	if s.accept('.') {
+		n.Length = dotted(n.Length)
+	}
+
+	if s.accept('-') {
+		if !s.done() {
+			return Note{}, s.fail("unexpected characters after rest")
+		}
+		return n, nil
+	}
+
+	sharp := s.accept('#')
+	c := s.peek()
+	s.pos++
+	semitone, ok := semitones[c]
+	if !ok || c == 'h' {
+		return Note{}, s.fail("bad note name")
+	}
+	if sharp {
+		if c == 'e' || c == 'b' {
+			return Note{}, s.fail("no such sharp")
+		}
+		semitone++
+	}
+
+	o, ok := s.number()
+	if !ok || o < 1 || o > 3 {
+		return Note{}, s.fail("bad octave")
+	}
+	if !s.done() {
+		return Note{}, s.fail("unexpected characters")
+	}
+	n.Pitch = pitch(semitone, composerOctave+o-1)
+	return n, nil
+}
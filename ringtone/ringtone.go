@@ -0,0 +1,129 @@
+// Package ringtone reads monophonic tunes written in the ringtone formats of
+// old mobile phones: RTTTL and the Nokia Composer
+package ringtone
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rest is the pitch of a note that's silent
+const Rest = -1
+
+// Note is one tone of a tune
+type Note struct {
+	Pitch  int // MIDI note number, 69 is A4 at 440Hz, or Rest
+	Length time.Duration
+}
+
+// Frequency is the pitch of the note in Hz
+func (n Note) Frequency() float64 {
+	return 440 * math.Pow(2, float64(n.Pitch-69)/12)
+}
+
+// SyntaxError reports where a ringtone is malformed
+type SyntaxError struct {
+	Offset int    // Byte offset of the bad token in the input
+	Token  string // The bad token
+	Msg    string // What's wrong with it
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ringtone: %s at offset %d: %q", e.Msg, e.Offset, e.Token)
+}
+
+var semitones = map[byte]int{'c': 0, 'd': 2, 'e': 4, 'f': 5, 'g': 7, 'a': 9, 'b': 11, 'h': 11}
+
+// wholeNote is how long a whole note lasts at a tempo in beats per minute,
+// where a beat is a quarter note
+func wholeNote(bpm int) time.Duration {
+	return 4 * time.Minute / time.Duration(bpm)
+}
+
+// validDuration tells if d is one of the note lengths phones can play
+func validDuration(d int) bool {
+	return d >= 1 && d <= 32 && d&(d-1) == 0
+}
+
+// token is one piece of a comma or space separated list, with where it starts
+type token struct {
+	text   string
+	offset int
+}
+
+// split breaks s into tokens separated by any of seps, dropping empty ones
+// and surrounding spaces, offset is where s starts in the whole input
+func split(s string, offset int, seps string) []token {
+	var tokens []token
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && !strings.ContainsRune(seps, rune(s[i])) {
+			continue
+		}
+		text := s[start:i]
+		trimmed := strings.TrimLeft(text, " \t\r\n")
+		lead := len(text) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, " \t\r\n")
+		if trimmed != "" {
+			tokens = append(tokens, token{trimmed, offset + start + lead})
+		}
+		start = i + 1
+	}
+	return tokens
+}
+
+// scanner reads a token a character at a time
+type scanner struct {
+	token
+	pos int
+}
+
+func (s *scanner) peek() byte {
+	if s.pos < len(s.text) {
+		return s.text[s.pos]
+	}
+	return 0
+}
+
+func (s *scanner) accept(c byte) bool {
+	if s.peek() == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// number reads a decimal number, ok is false if there isn't one
+func (s *scanner) number() (n int, ok bool) {
+	start := s.pos
+	for s.peek() >= '0' && s.peek() <= '9' {
+		s.pos++
+	}
+	if start == s.pos {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s.text[start:s.pos])
+	return n, err == nil
+}
+
+func (s *scanner) done() bool {
+	return s.pos == len(s.text)
+}
+
+func (s *scanner) fail(msg string) error {
+	return &SyntaxError{Offset: s.offset, Token: s.text, Msg: msg}
+}
+
+// dotted makes a note half as long again
+func dotted(d time.Duration) time.Duration {
+	return d + d/2
+}
+
+// pitch works out the MIDI note number of a note name in an octave where 4 is
+// the octave of middle C
+func pitch(semitone, octave int) int {
+	return (octave+1)*12 + semitone
+}
@@ -0,0 +1,97 @@
+package ringtone
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseComposer(t *testing.T) {
+	for _, data := range []struct {
+		Tune   string
+		Want   []Note
+		Reason string
+	}{
+		{"4a1", []Note{{81, 500 * time.Millisecond}}, "quarter note is one beat"},
+		{"16c2 8#d2", []Note{{84, 125 * time.Millisecond}, {87, 250 * time.Millisecond}}, "sharps raise a semitone"},
+		{"8.g3", []Note{{103, 375 * time.Millisecond}}, "dots add half the length"},
+		{"2-", []Note{{Rest, time.Second}}, "dash is a rest"},
+		{"  1c1\n32b3 ", []Note{{72, 2 * time.Second}, {107, 62500 * time.Microsecond}}, "any whitespace separates notes"},
+		{"", nil, "empty tune has no notes"},
+	} {
+		got, err := ParseComposer(120, data.Tune)
+		if err != nil || !reflect.DeepEqual(got, data.Want) {
+			t.Errorf("ParseComposer(%q) was %v, %v, want %v, because: %s", data.Tune, got, err, data.Want, data.Reason)
+		}
+	}
+}
+
+func TestParseComposerErrors(t *testing.T) {
+	for _, data := range []struct {
+		Tune   string
+		Offset int
+		Reason string
+	}{
+		{"3c1", 0, "durations are powers of two"},
+		{"64c1", 0, "durations are at most 32"},
+		{"4c1 c1", 4, "duration is required"},
+		{"4c1 4x1", 4, "unknown note name"},
+		{"4c4", 0, "octaves are 1 to 3"},
+		{"4c", 0, "octave is required"},
+		{"4#e1", 0, "E has no sharp"},
+		{"4-1", 0, "rests have no octave"},
+		{"4c1 4c1 4c1x", 8, "trailing characters"},
+	} {
+		_, err := ParseComposer(120, data.Tune)
+		var syntax *SyntaxError
+		if !errors.As(err, &syntax) || syntax.Offset != data.Offset {
+			t.Errorf("ParseComposer(%q) error was %v, want one at offset %d, because: %s", data.Tune, err, data.Offset, data.Reason)
+		}
+	}
+}
+
+func TestParseRTTTL(t *testing.T) {
+	name, got, err := ParseRTTTL("Tune:d=4,o=5,b=120:8c,e.,g6,2p,c#,16a.4")
+	want := []Note{
+		{72, 250 * time.Millisecond},
+		{76, 750 * time.Millisecond},
+		{91, 500 * time.Millisecond},
+		{Rest, time.Second},
+		{73, 500 * time.Millisecond},
+		{69, 187500 * time.Microsecond},
+	}
+	if err != nil || name != "Tune" || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRTTTL was %q, %v, %v, want %q, %v", name, got, err, "Tune", want)
+	}
+
+	// Defaults are optional
+	_, got, err = ParseRTTTL("x::a")
+	if err != nil || !reflect.DeepEqual(got, []Note{{93, wholeNote(rtttlBPM) / 4}}) {
+		t.Errorf("ParseRTTTL with no defaults was %v, %v", got, err)
+	}
+}
+
+func TestParseRTTTLErrors(t *testing.T) {
+	for _, data := range []struct {
+		Tune   string
+		Offset int
+		Reason string
+	}{
+		{"Tune:d=4", 0, "notes section is required"},
+		{"Tune:d=3:c", 5, "bad default duration"},
+		{"Tune:o=9:c", 5, "bad default octave"},
+		{"Tune:x=1:c", 5, "unknown default"},
+		{"Tune:b=0:c", 5, "bad tempo"},
+		{"Tune:d=4, o=5:c,x", 16, "bad note name"},
+		{"Tune::c,c9", 8, "bad octave"},
+		{"Tune::c,p#", 8, "rests can't be sharp"},
+		{"Tune::c.5.", 6, "only one dot"},
+	} {
+		_, _, err := ParseRTTTL(data.Tune)
+		var syntax *SyntaxError
+		if !errors.As(err, &syntax) || syntax.Offset != data.Offset {
+			t.Errorf("ParseRTTTL(%q) error was %v, want one at offset %d, because: %s", data.Tune, err, data.Offset, data.Reason)
+		}
+	}
+}
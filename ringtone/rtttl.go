@@ -0,0 +1,115 @@
+package ringtone
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RTTTL defaults used when a ringtone leaves them out
+const (
+	rtttlDuration = 4
+	rtttlOctave   = 6
+	rtttlBPM      = 63
+)
+
+// ParseRTTTL reads a ringtone in the Ring Tone Text Transfer Language, e.g.
+// "Tune:d=4,o=5,b=125:8c,8e,g,2c6,p" and returns its name and notes
+func ParseRTTTL(s string) (name string, notes []Note, err error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return "", nil, &SyntaxError{Token: s, Msg: "want name, defaults and notes separated by colons"}
+	}
+	name = strings.TrimSpace(parts[0])
+	defaultsAt := len(parts[0]) + 1
+	notesAt := defaultsAt + len(parts[1]) + 1
+
+	duration, octave, bpm := rtttlDuration, rtttlOctave, rtttlBPM
+	for _, t := range split(parts[1], defaultsAt, ",") {
+		key, value, ok := strings.Cut(t.text, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil {
+			return "", nil, &SyntaxError{t.offset, t.text, "bad default"}
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "d":
+			if !validDuration(n) {
+				return "", nil, &SyntaxError{t.offset, t.text, "bad default duration"}
+			}
+			duration = n
+		case "o":
+			if n < 3 || n > 8 {
+				return "", nil, &SyntaxError{t.offset, t.text, "bad default octave"}
+			}
+			octave = n
+		case "b":
+			if n <= 0 {
+				return "", nil, &SyntaxError{t.offset, t.text, "bad tempo"}
+			}
+			bpm = n
+		default:
+			return "", nil, &SyntaxError{t.offset, t.text, "unknown default"}
+		}
+	}
+
+	whole := wholeNote(bpm)
+	for _, t := range split(parts[2], notesAt, ",") {
+		n, err := parseRTTTLNote(t, whole, duration, octave)
+		if err != nil {
+			return "", nil, err
+		}
+		notes = append(notes, n)
+	}
+	return name, notes, nil
+}
+
+// parseRTTTLNote reads one note, e.g. "8c#6." or "p"
+func parseRTTTLNote(t token, whole time.Duration, duration, octave int) (Note, error) {
+	s := &scanner{token: token{strings.ToLower(t.text), t.offset}}
+	if d, ok := s.number(); ok {
+		if !validDuration(d) {
+			return Note{}, s.fail("bad duration")
+		}
+		duration = d
+	}
+
+	c := s.peek()
+	s.pos++
+	semitone, ok := semitones[c]
+	if c != 'p' && !ok {
+		return Note{}, s.fail("bad note name")
+	}
+	if s.accept('#') {
+		if c == 'p' {
+			return Note{}, s.fail("rests can't be sharp")
+		}
+		semitone++
+	}
+
+	// The dot is allowed before or after the octave
+	dot := s.accept('.')
+	if o, ok := s.number(); ok {
+		if o < 3 || o > 8 {
+			return Note{}, s.fail("bad octave")
+		}
+		octave = o
+	}
+	if s.accept('.') {
+		if dot {
+			return Note{}, s.fail("more than one dot")
+		}
+		dot = true
+	}
+	if !s.done() {
+		return Note{}, s.fail("unexpected characters")
+	}
+
+	n := Note{Pitch: Rest, Length: whole / time.Duration(duration)}
+	if c != 'p' {
+		n.Pitch = pitch(semitone, octave)
+	}
+	if dot {
+		n.Length = dotted(n.Length)
+	}
+	return n, nil
+}
@@ -0,0 +1,28 @@
+#!/bin/bash
+
+if [[ $1 == "-h" ]]; then
+	echo "this script builds the web version of the game into dist/web, ready to be served or uploaded" >&2
+	exit 0
+fi
+
+set -euo pipefail
+
+out="dist/web"
+
+echo "Building $out/freefall.wasm"
+GOOS=js GOARCH=wasm go build -ldflags "-w -s" -o "$out/freefall.wasm" .
+
+# wasm_exec.js moved from misc/wasm to lib/wasm in Go 1.24
+goroot="$(go env GOROOT)"
+for exec in "$goroot/lib/wasm/wasm_exec.js" "$goroot/misc/wasm/wasm_exec.js"; do
+	if [[ -f "$exec" ]]; then
+		cp "$exec" "$out/"
+		break
+	fi
+done
+if [[ ! -f "$out/wasm_exec.js" ]]; then
+	echo "couldn't find wasm_exec.js in $goroot" >&2
+	exit 1
+fi
+
+echo "Done, try it with: python3 -m http.server -d $out"